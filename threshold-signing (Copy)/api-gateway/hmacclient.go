@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// hmacAlgorithm is the Authorization scheme the gateway signs its outbound
+// MessageBoard requests with; it must match message-board's hmacauth.go
+// exactly, since the board verifies against the same scheme.
+const hmacAlgorithm = "MPC-HMAC-SHA256"
+
+// hmacDateLayout is the yyyymmdd date used both in the Authorization
+// header's Credential scope and to derive the day's signing key.
+const hmacDateLayout = "20060102"
+
+// hmacSignedHeaders is the fixed, ordered set of headers every signed
+// request includes, matching message-board's requirement exactly.
+var hmacSignedHeaders = []string{"host", "x-mpc-date", "x-mpc-node"}
+
+// NodeCredential is the access_key_id/secret_key pair the gateway is
+// provisioned with out of band (see MESSAGE_BOARD_CREDENTIAL_PATH), used to
+// sign every mutating request the gateway sends to the MessageBoard. The
+// gateway is just another authenticated caller of the board's node API, so
+// this mirrors message-board's own NodeCredential field-for-field.
+type NodeCredential struct {
+	NodeID      string `json:"node_id"`
+	AccessKeyID string `json:"access_key_id"`
+	SecretKey   string `json:"secret_key"`
+}
+
+// canonicalRequest builds the SigV4-style string that is actually signed,
+// matching message-board's canonicalRequest exactly: the method, path, the
+// sorted-and-encoded query string, the signed headers (in
+// hmacSignedHeaders order) each as "name:value\n", the semicolon-joined
+// list of signed header names, and the hex-encoded SHA256 of the body.
+func canonicalRequest(method, path string, query url.Values, headers http.Header, body []byte) string {
+	var canonicalQuery string
+	if len(query) > 0 {
+		keys := make([]string, 0, len(query))
+		for k := range query {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		parts := make([]string, 0, len(query))
+		for _, k := range keys {
+			values := append([]string(nil), query[k]...)
+			sort.Strings(values)
+			for _, v := range values {
+				parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+			}
+		}
+		canonicalQuery = strings.Join(parts, "&")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, h := range hmacSignedHeaders {
+		canonicalHeaders.WriteString(h)
+		canonicalHeaders.WriteByte(':')
+		canonicalHeaders.WriteString(strings.TrimSpace(headers.Get(h)))
+		canonicalHeaders.WriteByte('\n')
+	}
+
+	bodyHash := sha256.Sum256(body)
+
+	return strings.Join([]string{
+		method,
+		path,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		strings.Join(hmacSignedHeaders, ";"),
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// deriveSigningKey derives the day's signing key for secret and date
+// (formatted per hmacDateLayout): HMAC(secret, date) -> HMAC(_, "mpc-board").
+// Must match message-board's deriveSigningKey exactly.
+func deriveSigningKey(secret, date string) []byte {
+	dateKey := hmacSum([]byte(secret), []byte(date))
+	return hmacSum(dateKey, []byte("mpc-board"))
+}
+
+func hmacSum(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// signRequest computes the Authorization header value the gateway sends
+// when calling the board as cred, for a request with the given method,
+// path, query, Host header, X-Mpc-Date header (RFC3339), and X-Mpc-Node
+// header. Matches message-board's signRequest, which the board's
+// verifyNodeHMAC recomputes and compares against.
+func signRequest(cred NodeCredential, method, path string, query url.Values, host, xMpcDate, xMpcNode string, body []byte) (string, error) {
+	t, err := time.Parse(time.RFC3339, xMpcDate)
+	if err != nil {
+		return "", fmt.Errorf("x-mpc-date must be RFC3339: %w", err)
+	}
+	date := t.UTC().Format(hmacDateLayout)
+
+	headers := http.Header{}
+	headers.Set("Host", host)
+	headers.Set("X-Mpc-Date", xMpcDate)
+	headers.Set("X-Mpc-Node", xMpcNode)
+
+	canonical := canonicalRequest(method, path, query, headers, body)
+	signingKey := deriveSigningKey(cred.SecretKey, date)
+	signature := hex.EncodeToString(hmacSum(signingKey, []byte(canonical)))
+
+	return fmt.Sprintf("%s Credential=%s/%s/mpc-board, SignedHeaders=%s, Signature=%s",
+		hmacAlgorithm, cred.AccessKeyID, date, strings.Join(hmacSignedHeaders, ";"), signature), nil
+}
+
+// HMACClient signs outgoing requests as cred and sends them to baseURL, so
+// the gateway's calls into the MessageBoard carry the Authorization header
+// requireNodeHMAC now requires of every mutating request.
+type HMACClient struct {
+	cred       NodeCredential
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHMACClient creates an HMACClient that signs requests as cred and sends
+// them to baseURL using httpClient (or http.DefaultClient if nil).
+func NewHMACClient(cred NodeCredential, baseURL string, httpClient *http.Client) *HMACClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &HMACClient{cred: cred, baseURL: strings.TrimRight(baseURL, "/"), httpClient: httpClient}
+}
+
+// Do sends an HTTP request for method and path (e.g. "/requests"), with
+// query appended and body sent as the request body, signed with the
+// client's credential under the current time.
+func (c *HMACClient) Do(method, path string, query url.Values, body []byte) (*http.Response, error) {
+	u := c.baseURL + path
+	if encoded := query.Encode(); encoded != "" {
+		u += "?" + encoded
+	}
+
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	xMpcDate := time.Now().UTC().Format(time.RFC3339)
+
+	req.Header.Set("Host", host)
+	req.Header.Set("X-Mpc-Date", xMpcDate)
+	req.Header.Set("X-Mpc-Node", c.cred.NodeID)
+	req.Header.Set("Content-Type", "application/json")
+
+	authHeader, err := signRequest(c.cred, method, req.URL.Path, query, host, xMpcDate, c.cred.NodeID, body)
+	if err != nil {
+		return nil, fmt.Errorf("signing request: %w", err)
+	}
+	req.Header.Set("Authorization", authHeader)
+
+	return c.httpClient.Do(req)
+}
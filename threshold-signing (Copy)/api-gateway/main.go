@@ -1,19 +1,36 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
+	"strings"
 )
 
 // messageBoardURL is the base URL for the MessageBoard service
 // It is configured via environment variable at startup
 var messageBoardURL string
 
+// board signs every outbound call to the MessageBoard with the gateway's
+// own NodeCredential (see MESSAGE_BOARD_CREDENTIAL_PATH): the board's
+// requireNodeHMAC rejects any unsigned mutating request, and the gateway is
+// just another authenticated caller of its node API.
+var board *HMACClient
+
+// oidc validates bearer JWTs on /sign, /status/, and /publickey against
+// OIDC_ISSUER_URL. adminAudience is the "aud" value a token must carry to
+// reach /admin/policies, set via OIDC_ADMIN_AUDIENCE.
+var (
+	oidc          *oidcVerifier
+	adminAudience string
+	policies      = NewPolicyStore()
+)
+
 func main() {
 	// Configure MessageBoard URL from environment
 	messageBoardURL = os.Getenv("MESSAGE_BOARD_URL")
@@ -21,23 +38,110 @@ func main() {
 		messageBoardURL = "http://message-board:8080"
 	}
 
+	issuer := os.Getenv("OIDC_ISSUER_URL")
+	if issuer == "" {
+		log.Fatal("OIDC_ISSUER_URL is required")
+	}
+	oidc = newOIDCVerifier(issuer)
+
+	// Load the gateway's own NodeCredential so its outbound calls to the
+	// MessageBoard carry a valid Authorization header; without this, every
+	// mutating call (e.g. POST /requests) is rejected by requireNodeHMAC.
+	credPath := os.Getenv("MESSAGE_BOARD_CREDENTIAL_PATH")
+	if credPath == "" {
+		log.Fatal("MESSAGE_BOARD_CREDENTIAL_PATH is required")
+	}
+	cred, err := loadMessageBoardCredential(credPath)
+	if err != nil {
+		log.Fatalf("loading message board credential: %v", err)
+	}
+	board = NewHMACClient(cred, messageBoardURL, nil)
+
+	adminAudience = os.Getenv("OIDC_ADMIN_AUDIENCE")
+	if adminAudience == "" {
+		log.Fatal("OIDC_ADMIN_AUDIENCE is required")
+	}
+
 	// Get port from environment variable, default to 8000
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8000"
 	}
 
-	// Register HTTP handlers
+	// Register HTTP handlers. /sign, /status/, and /publickey require a
+	// bearer JWT from the configured issuer; /admin/policies additionally
+	// requires that token to carry the admin audience.
 	http.HandleFunc("/health", handleHealth)
-	http.HandleFunc("/publickey", handlePublicKey)
-	http.HandleFunc("/sign", handleSignRequest)
-	http.HandleFunc("/status/", handleStatusRequest)
+	http.HandleFunc("/publickey", requireOIDC(handlePublicKey))
+	http.HandleFunc("/sign", requireOIDC(handleSignRequest))
+	http.HandleFunc("/status/", requireOIDC(handleStatusRequest))
+	http.HandleFunc("/admin/policies", requireAdminOIDC(handleAdminPolicies))
 
 	// Start the HTTP server
 	log.Printf("API Gateway starting on port %s", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
 
+// loadMessageBoardCredential reads a single NodeCredential JSON object from
+// path; this is the credential the gateway signs its own outbound
+// MessageBoard requests with, provisioned into the board's
+// MPC_BOARD_CREDENTIALS_PATH out of band.
+func loadMessageBoardCredential(path string) (NodeCredential, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return NodeCredential{}, fmt.Errorf("reading %s: %w", path, err)
+	}
+	var cred NodeCredential
+	if err := json.Unmarshal(data, &cred); err != nil {
+		return NodeCredential{}, fmt.Errorf("decoding %s: %w", path, err)
+	}
+	return cred, nil
+}
+
+// requireOIDC wraps next so it only runs once the request carries a bearer
+// JWT that verifies against oidc; the verified Claims are attached to the
+// request context for next to read with claimsFromContext.
+func requireOIDC(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, err := authenticate(r)
+		if err != nil {
+			log.Printf("Rejected request to %s: %v", r.URL.Path, err)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), claimsContextKey{}, claims)))
+	}
+}
+
+// requireAdminOIDC is requireOIDC plus a check that the token's audience
+// includes adminAudience, so the admin policy endpoints need a token minted
+// for a distinct admin client rather than any signer client's token.
+func requireAdminOIDC(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, err := authenticate(r)
+		if err != nil {
+			log.Printf("Rejected admin request to %s: %v", r.URL.Path, err)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if !claims.HasAudience(adminAudience) {
+			log.Printf("Rejected admin request to %s: token missing admin audience", r.URL.Path)
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), claimsContextKey{}, claims)))
+	}
+}
+
+// authenticate extracts and verifies the bearer token on r.
+func authenticate(r *http.Request) (*Claims, error) {
+	token, err := bearerToken(r)
+	if err != nil {
+		return nil, err
+	}
+	return oidc.VerifyToken(token)
+}
+
 // handleHealth responds to health check requests
 // This is used by container orchestration to verify the service is running
 func handleHealth(w http.ResponseWriter, r *http.Request) {
@@ -46,6 +150,7 @@ func handleHealth(w http.ResponseWriter, r *http.Request) {
 }
 
 // handlePublicKey returns the shared public key from the MessageBoard
+// GET /publickey?scheme=bls12381 - defaults to "ecdsa_secp256k1" when omitted
 // This public key can be used to verify any signatures produced by the threshold signing system
 func handlePublicKey(w http.ResponseWriter, r *http.Request) {
 	// Only accept GET requests
@@ -54,8 +159,13 @@ func handlePublicKey(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	scheme := r.URL.Query().Get("scheme")
+	if scheme == "" {
+		scheme = SchemeECDSASecp256k1
+	}
+
 	// Query the MessageBoard for the public key
-	publicKey, err := queryPublicKey()
+	publicKey, err := queryPublicKey(scheme)
 	if err != nil {
 		log.Printf("Error querying public key: %v", err)
 		http.Error(w, "Failed to get public key", http.StatusInternalServerError)
@@ -65,15 +175,16 @@ func handlePublicKey(w http.ResponseWriter, r *http.Request) {
 	// Return the public key
 	response := PublicKeyResponse{
 		PublicKey: publicKey,
+		Scheme:    scheme,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// queryPublicKey retrieves the shared public key from the MessageBoard
-func queryPublicKey() (string, error) {
-	resp, err := http.Get(messageBoardURL + "/publickey")
+// queryPublicKey retrieves the shared public key for scheme from the MessageBoard
+func queryPublicKey(scheme string) (string, error) {
+	resp, err := board.Do(http.MethodGet, "/publickey", url.Values{"scheme": {scheme}}, nil)
 	if err != nil {
 		return "", err
 	}
@@ -98,7 +209,8 @@ func queryPublicKey() (string, error) {
 }
 
 // handleSignRequest processes incoming signing requests
-// It validates the request, forwards it to the MessageBoard, and returns a request ID
+// It validates the request against the caller's Policy, forwards it to the
+// MessageBoard, and returns a request ID
 func handleSignRequest(w http.ResponseWriter, r *http.Request) {
 	// Only accept POST requests
 	if r.Method != http.MethodPost {
@@ -119,9 +231,27 @@ func handleSignRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	claims, ok := claimsFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	policy, err := policies.ForSubjects(claims.PolicySubjects())
+	if err != nil {
+		log.Printf("Rejected sign request from %s: %v", claims.Subject, err)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+	if err := policies.Authorize(policy, req.Message); err != nil {
+		log.Printf("Rejected sign request from %s: %v", claims.Subject, err)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
 	// Forward the signing request to the MessageBoard
 	// The MessageBoard will coordinate the threshold signing protocol
-	requestID, err := forwardToMessageBoard(req.Message)
+	requestID, err := forwardToMessageBoard(req.Message, req.Scheme, claims.Subject)
 	if err != nil {
 		log.Printf("Error forwarding to message board: %v", err)
 		http.Error(w, "Failed to process signing request", http.StatusInternalServerError)
@@ -141,22 +271,20 @@ func handleSignRequest(w http.ResponseWriter, r *http.Request) {
 
 // forwardToMessageBoard sends a signing request to the MessageBoard service
 // Returns the request ID assigned by the MessageBoard
-func forwardToMessageBoard(message string) (string, error) {
+func forwardToMessageBoard(message, scheme, requestedBy string) (string, error) {
 	// Prepare the request payload
 	data := map[string]string{
-		"message": message,
+		"message":      message,
+		"scheme":       scheme,
+		"requested_by": requestedBy,
 	}
 	jsonData, err := json.Marshal(data)
 	if err != nil {
 		return "", err
 	}
 
-	// Send HTTP POST to MessageBoard
-	resp, err := http.Post(
-		messageBoardURL+"/requests",
-		"application/json",
-		bytes.NewBuffer(jsonData),
-	)
+	// Send a signed HTTP POST to MessageBoard
+	resp, err := board.Do(http.MethodPost, "/requests", nil, jsonData)
 	if err != nil {
 		return "", err
 	}
@@ -184,6 +312,8 @@ func forwardToMessageBoard(message string) (string, error) {
 
 // handleStatusRequest checks the status of a signing request
 // URL format: /status/{request_id}
+// A client that sends "Accept: text/event-stream" is upgraded to an SSE
+// stream of status transitions instead, see streamStatus.
 func handleStatusRequest(w http.ResponseWriter, r *http.Request) {
 	// Only accept GET requests
 	if r.Method != http.MethodGet {
@@ -199,6 +329,11 @@ func handleStatusRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		streamStatus(w, r, requestID)
+		return
+	}
+
 	// Query the MessageBoard for the current status
 	status, signature, err := queryMessageBoard(requestID)
 	if err != nil {
@@ -218,11 +353,57 @@ func handleStatusRequest(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// streamStatus proxies the MessageBoard's SSE status stream
+// (GET /requests/{id}/status/stream) back to the client, so a client
+// polling /status/{id} with "Accept: text/event-stream" sees status
+// transitions as they happen instead of re-requesting on a timer.
+func streamStatus(w http.ResponseWriter, r *http.Request, requestID string) {
+	upstream, err := board.Do(http.MethodGet, "/requests/"+requestID+"/status/stream", nil, nil)
+	if err != nil {
+		log.Printf("Error opening status stream for %s: %v", requestID, err)
+		http.Error(w, "Failed to open status stream", http.StatusInternalServerError)
+		return
+	}
+	defer upstream.Body.Close()
+
+	if upstream.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(upstream.Body)
+		log.Printf("message board status stream for %s returned %d: %s", requestID, upstream.StatusCode, body)
+		http.Error(w, "Failed to open status stream", http.StatusInternalServerError)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	buf := make([]byte, 4096)
+	for {
+		n, err := upstream.Body.Read(buf)
+		if n > 0 {
+			if _, writeErr := w.Write(buf[:n]); writeErr != nil {
+				return
+			}
+			flusher.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
 // queryMessageBoard retrieves the status and signature (if ready) for a request
 // Returns status string, signature string (empty if not ready), and error
 func queryMessageBoard(requestID string) (string, string, error) {
-	// Send GET request to MessageBoard
-	resp, err := http.Get(messageBoardURL + "/requests/" + requestID)
+	// Send a signed GET request to MessageBoard
+	resp, err := board.Do(http.MethodGet, "/requests/"+requestID, nil, nil)
 	if err != nil {
 		return "", "", err
 	}
@@ -251,3 +432,44 @@ func queryMessageBoard(requestID string) (string, string, error) {
 
 	return status, signature, nil
 }
+
+// handleAdminPolicies manages Policy registrations
+// GET /admin/policies - lists every registered policy
+// POST /admin/policies - creates or replaces the policy for body.Subject
+// DELETE /admin/policies?subject=X - removes the policy for X
+// Requires a bearer JWT carrying adminAudience; see requireAdminOIDC.
+func handleAdminPolicies(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"policies": policies.List(),
+		})
+
+	case http.MethodPost:
+		var p Policy
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		if err := policies.Set(p); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+
+	case http.MethodDelete:
+		subject := r.URL.Query().Get("subject")
+		if subject == "" {
+			http.Error(w, "subject parameter is required", http.StatusBadRequest)
+			return
+		}
+		policies.Delete(subject)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnauthorized is returned (wrapped) when a bearer token is missing,
+// expired, or fails signature verification. requireOIDC and
+// requireAdminOIDC answer 401 for any error from authenticate, this one
+// included.
+var ErrUnauthorized = fmt.Errorf("unauthorized")
+
+// jwksRefreshInterval bounds how long a fetched JWKS is trusted before
+// oidcVerifier refetches it, so a rotated signing key is picked up without
+// requiring a gateway restart.
+const jwksRefreshInterval = 1 * time.Hour
+
+// claimsContextKey is the context.Context key handleSignRequest uses to
+// retrieve the verified Claims for the current request, set by
+// requireOIDC/requireAdminOIDC.
+type claimsContextKey struct{}
+
+// Claims is the subset of an OIDC ID/access token's claims the gateway
+// acts on: who the caller is (for policy lookup and RequestedBy) and what
+// audiences the token was issued for (for the admin-endpoint check).
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Groups    []string `json:"groups,omitempty"`
+	Audience  []string `json:"-"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+// rawClaims is the token payload as decoded from JSON. Audience is kept raw
+// because the "aud" claim comes in either its single-string or string-array
+// JSON form, since different IdPs encode it differently (see decodeAudience).
+type rawClaims struct {
+	Subject   string          `json:"sub"`
+	Groups    []string        `json:"groups"`
+	Audience  json.RawMessage `json:"aud"`
+	ExpiresAt int64           `json:"exp"`
+}
+
+// HasAudience reports whether aud is among the token's audiences.
+func (c *Claims) HasAudience(aud string) bool {
+	for _, a := range c.Audience {
+		if a == aud {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicySubjects returns the keys to look Policy up by: the token subject
+// first, then each group claim, so a caller can be granted a policy either
+// individually or via group membership.
+func (c *Claims) PolicySubjects() []string {
+	subjects := make([]string, 0, 1+len(c.Groups))
+	subjects = append(subjects, c.Subject)
+	subjects = append(subjects, c.Groups...)
+	return subjects
+}
+
+// oidcVerifier validates bearer JWTs against a single OIDC issuer: it
+// auto-discovers the JWKS endpoint from the issuer's
+// .well-known/openid-configuration document, caches the fetched keys, and
+// refetches them when a token names an unknown kid (handles rotation)
+// or the cache has aged past jwksRefreshInterval.
+type oidcVerifier struct {
+	issuer string
+
+	mu        sync.RWMutex
+	keys      map[string]*rsa.PublicKey
+	fetchedAt time.Time
+}
+
+// newOIDCVerifier creates a verifier for issuer. The JWKS is fetched lazily
+// on the first VerifyToken call rather than here, so a misconfigured or
+// temporarily unreachable issuer doesn't prevent the gateway from starting.
+func newOIDCVerifier(issuer string) *oidcVerifier {
+	return &oidcVerifier{issuer: strings.TrimRight(issuer, "/")}
+}
+
+type openIDConfiguration struct {
+	JWKSURI string `json:"jwks_uri"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type jsonWebKey struct {
+	Kid string   `json:"kid"`
+	Kty string   `json:"kty"`
+	N   string   `json:"n"`
+	E   string   `json:"e"`
+	X5c []string `json:"x5c"`
+}
+
+// refresh fetches the issuer's discovery document and JWKS, replacing the
+// cached key set. Called with the verifier unlocked; it takes the write
+// lock only to install the new keys.
+func (v *oidcVerifier) refresh() error {
+	discoveryURL := v.issuer + "/.well-known/openid-configuration"
+	resp, err := http.Get(discoveryURL)
+	if err != nil {
+		return fmt.Errorf("fetching OIDC discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var discovery openIDConfiguration
+	if err := json.NewDecoder(resp.Body).Decode(&discovery); err != nil {
+		return fmt.Errorf("decoding OIDC discovery document: %w", err)
+	}
+	if discovery.JWKSURI == "" {
+		return fmt.Errorf("OIDC discovery document for %s has no jwks_uri", v.issuer)
+	}
+
+	jwksResp, err := http.Get(discovery.JWKSURI)
+	if err != nil {
+		return fmt.Errorf("fetching JWKS: %w", err)
+	}
+	defer jwksResp.Body.Close()
+
+	var jwks jsonWebKeySet
+	if err := json.NewDecoder(jwksResp.Body).Decode(&jwks); err != nil {
+		return fmt.Errorf("decoding JWKS: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(jwks.Keys))
+	for _, key := range jwks.Keys {
+		if key.Kty != "RSA" {
+			continue
+		}
+		pub, err := rsaPublicKeyFromJWK(key)
+		if err != nil {
+			return fmt.Errorf("parsing JWK %s: %w", key.Kid, err)
+		}
+		keys[key.Kid] = pub
+	}
+
+	v.mu.Lock()
+	v.keys = keys
+	v.fetchedAt = time.Now()
+	v.mu.Unlock()
+	return nil
+}
+
+// rsaPublicKeyFromJWK decodes an RSA JWK's modulus (n) and exponent (e)
+// into a *rsa.PublicKey.
+func rsaPublicKeyFromJWK(key jsonWebKey) (*rsa.PublicKey, error) {
+	nBytes, err := base64.RawURLEncoding.DecodeString(key.N)
+	if err != nil {
+		return nil, fmt.Errorf("decoding modulus: %w", err)
+	}
+	eBytes, err := base64.RawURLEncoding.DecodeString(key.E)
+	if err != nil {
+		return nil, fmt.Errorf("decoding exponent: %w", err)
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nBytes),
+		E: int(new(big.Int).SetBytes(eBytes).Int64()),
+	}, nil
+}
+
+// keyFor returns the RSA public key for kid, fetching or refetching the
+// JWKS first if it's empty, stale, or doesn't contain kid (the key may have
+// rotated in since the last fetch).
+func (v *oidcVerifier) keyFor(kid string) (*rsa.PublicKey, error) {
+	v.mu.RLock()
+	key, ok := v.keys[kid]
+	stale := time.Since(v.fetchedAt) > jwksRefreshInterval
+	v.mu.RUnlock()
+
+	if ok && !stale {
+		return key, nil
+	}
+
+	if err := v.refresh(); err != nil {
+		return nil, err
+	}
+
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	key, ok = v.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("%w: no JWKS key for kid %q", ErrUnauthorized, kid)
+	}
+	return key, nil
+}
+
+// VerifyToken parses and verifies tokenString as an RS256-signed JWT
+// issued by v.issuer, checking signature and expiry, and returns its
+// Claims.
+func (v *oidcVerifier) VerifyToken(tokenString string) (*Claims, error) {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed JWT", ErrUnauthorized)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT header: %v", ErrUnauthorized, err)
+	}
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT header: %v", ErrUnauthorized, err)
+	}
+	if header.Alg != "RS256" {
+		return nil, fmt.Errorf("%w: unsupported JWT alg %q", ErrUnauthorized, header.Alg)
+	}
+
+	key, err := v.keyFor(header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT signature: %v", ErrUnauthorized, err)
+	}
+	signingInput := parts[0] + "." + parts[1]
+	digest := sha256.Sum256([]byte(signingInput))
+	if err := rsaVerifyPKCS1v15SHA256(key, digest[:], signature); err != nil {
+		return nil, fmt.Errorf("%w: signature does not verify: %v", ErrUnauthorized, err)
+	}
+
+	payloadJSON, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT payload: %v", ErrUnauthorized, err)
+	}
+	var raw rawClaims
+	if err := json.Unmarshal(payloadJSON, &raw); err != nil {
+		return nil, fmt.Errorf("%w: malformed JWT payload: %v", ErrUnauthorized, err)
+	}
+
+	if raw.ExpiresAt != 0 && time.Now().Unix() >= raw.ExpiresAt {
+		return nil, fmt.Errorf("%w: token expired", ErrUnauthorized)
+	}
+
+	claims := &Claims{Subject: raw.Subject, Groups: raw.Groups, ExpiresAt: raw.ExpiresAt}
+	claims.Audience = decodeAudience(raw.Audience)
+	return claims, nil
+}
+
+// decodeAudience parses the "aud" claim, which per the JWT spec may be
+// encoded as either a single string or an array of strings.
+func decodeAudience(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var multi []string
+	if err := json.Unmarshal(raw, &multi); err == nil {
+		return multi
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil && single != "" {
+		return []string{single}
+	}
+	return nil
+}
+
+// rsaVerifyPKCS1v15SHA256 checks sig against digest (a SHA-256 hash) using
+// pub, as required for the RS256 JWS algorithm.
+func rsaVerifyPKCS1v15SHA256(pub *rsa.PublicKey, digest, sig []byte) error {
+	return rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest, sig)
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>"
+// header, or returns an error if the header is missing or malformed.
+func bearerToken(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", fmt.Errorf("%w: missing Authorization header", ErrUnauthorized)
+	}
+	const prefix = "Bearer "
+	if !strings.HasPrefix(header, prefix) {
+		return "", fmt.Errorf("%w: Authorization header must be a Bearer token", ErrUnauthorized)
+	}
+	return strings.TrimPrefix(header, prefix), nil
+}
+
+// claimsFromContext retrieves the Claims a requireOIDC/requireAdminOIDC
+// wrapper stored on the request context.
+func claimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+	return claims, ok
+}
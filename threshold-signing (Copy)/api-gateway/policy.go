@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrPolicyNotFound is returned by PolicyStore.Get when no Policy is
+// registered for a subject. handleSignRequest treats it as a 403: a
+// caller with no policy has no granted scope, rather than defaulting to
+// unrestricted access.
+var ErrPolicyNotFound = fmt.Errorf("no policy configured for subject")
+
+// ErrPolicyDenied is returned (wrapped) by PolicyStore.Authorize when a
+// request matches a Policy's subject but is rejected by it: the message
+// doesn't match the allowed prefixes/pattern, or the caller's request rate
+// exceeds MaxRequestsPerMinute.
+var ErrPolicyDenied = fmt.Errorf("denied by policy")
+
+// Policy scopes what a given JWT subject (or group) may do through the
+// gateway: which messages it may request signatures for and how fast it
+// may request them.
+type Policy struct {
+	// Subject is the JWT "sub" claim, or a group name from the "groups"
+	// claim, this Policy applies to.
+	Subject string `json:"subject"`
+	// AllowedMessagePrefixes restricts Message to values starting with one
+	// of these. Ignored (no prefix restriction) if empty.
+	AllowedMessagePrefixes []string `json:"allowed_message_prefixes,omitempty"`
+	// AllowedMessagePattern, if set, restricts Message to values matching
+	// this regular expression. Applied in addition to
+	// AllowedMessagePrefixes, not instead of it.
+	AllowedMessagePattern string `json:"allowed_message_pattern,omitempty"`
+	// MaxRequestsPerMinute caps how many signing requests this subject may
+	// submit per rolling minute. Zero means no limit.
+	MaxRequestsPerMinute int `json:"max_requests_per_minute,omitempty"`
+
+	pattern *regexp.Regexp
+}
+
+// compile parses AllowedMessagePattern once so Authorize doesn't
+// re-compile the regexp on every call.
+func (p *Policy) compile() error {
+	if p.AllowedMessagePattern == "" {
+		p.pattern = nil
+		return nil
+	}
+	re, err := regexp.Compile(p.AllowedMessagePattern)
+	if err != nil {
+		return fmt.Errorf("invalid allowed_message_pattern: %w", err)
+	}
+	p.pattern = re
+	return nil
+}
+
+// rateWindow tracks MaxRequestsPerMinute usage for a single subject: count
+// requests made since windowStart, resetting once a minute has elapsed.
+type rateWindow struct {
+	windowStart time.Time
+	count       int
+}
+
+// PolicyStore holds the Policy registered for each subject, plus the
+// per-subject rate-limit bookkeeping Authorize uses to enforce
+// MaxRequestsPerMinute. Safe for concurrent use.
+type PolicyStore struct {
+	mu        sync.Mutex
+	policies  map[string]*Policy
+	rateUsage map[string]*rateWindow
+}
+
+// NewPolicyStore creates an empty PolicyStore.
+func NewPolicyStore() *PolicyStore {
+	return &PolicyStore{
+		policies:  make(map[string]*Policy),
+		rateUsage: make(map[string]*rateWindow),
+	}
+}
+
+// Set registers or replaces the Policy for p.Subject.
+func (ps *PolicyStore) Set(p Policy) error {
+	if p.Subject == "" {
+		return fmt.Errorf("policy subject is required")
+	}
+	if err := p.compile(); err != nil {
+		return err
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	ps.policies[p.Subject] = &p
+	return nil
+}
+
+// Get returns the Policy registered for subject, or ErrPolicyNotFound.
+func (ps *PolicyStore) Get(subject string) (*Policy, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	p, ok := ps.policies[subject]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPolicyNotFound, subject)
+	}
+	return p, nil
+}
+
+// Delete removes the Policy registered for subject, if any.
+func (ps *PolicyStore) Delete(subject string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+	delete(ps.policies, subject)
+	delete(ps.rateUsage, subject)
+}
+
+// List returns every registered Policy, in no particular order.
+func (ps *PolicyStore) List() []*Policy {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	result := make([]*Policy, 0, len(ps.policies))
+	for _, p := range ps.policies {
+		result = append(result, p)
+	}
+	return result
+}
+
+// ForSubjects returns the first Policy registered for any of subjects (the
+// caller's JWT subject followed by its group claims, in that order), or
+// ErrPolicyNotFound if none of them has one.
+func (ps *PolicyStore) ForSubjects(subjects []string) (*Policy, error) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	for _, subject := range subjects {
+		if p, ok := ps.policies[subject]; ok {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrPolicyNotFound, strings.Join(subjects, ", "))
+}
+
+// Authorize checks message against p's allowed prefixes/pattern and
+// consumes one unit of p's per-minute rate budget, returning
+// ErrPolicyDenied if either check fails.
+func (ps *PolicyStore) Authorize(p *Policy, message string) error {
+	if !messageAllowed(p, message) {
+		return fmt.Errorf("%w: message does not match policy for %s", ErrPolicyDenied, p.Subject)
+	}
+
+	if p.MaxRequestsPerMinute <= 0 {
+		return nil
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	usage, ok := ps.rateUsage[p.Subject]
+	now := time.Now()
+	if !ok || now.Sub(usage.windowStart) >= time.Minute {
+		usage = &rateWindow{windowStart: now}
+		ps.rateUsage[p.Subject] = usage
+	}
+	if usage.count >= p.MaxRequestsPerMinute {
+		return fmt.Errorf("%w: rate limit of %d/min exceeded for %s", ErrPolicyDenied, p.MaxRequestsPerMinute, p.Subject)
+	}
+	usage.count++
+	return nil
+}
+
+// messageAllowed reports whether message satisfies p's
+// AllowedMessagePrefixes and AllowedMessagePattern restrictions. A Policy
+// with neither set allows any message.
+func messageAllowed(p *Policy, message string) bool {
+	if len(p.AllowedMessagePrefixes) > 0 {
+		matched := false
+		for _, prefix := range p.AllowedMessagePrefixes {
+			if strings.HasPrefix(message, prefix) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if p.pattern != nil && !p.pattern.MatchString(message) {
+		return false
+	}
+	return true
+}
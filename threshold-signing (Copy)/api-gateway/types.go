@@ -1,10 +1,20 @@
 package main
 
+// Supported signature schemes for a SignRequest/PublicKeyResponse.
+// SchemeECDSASecp256k1 is the default and is used when Scheme is left empty.
+const (
+	SchemeECDSASecp256k1 = "ecdsa_secp256k1"
+	SchemeBLS12381       = "bls12381"
+)
+
 // SignRequest represents an incoming request to sign a message
 // The API Gateway receives this from external clients
 type SignRequest struct {
 	// Message is the data to be signed (hex-encoded or plain text)
 	Message string `json:"message"`
+	// Scheme selects the signature scheme ("ecdsa_secp256k1" or "bls12381")
+	// Defaults to "ecdsa_secp256k1" when omitted
+	Scheme string `json:"scheme,omitempty"`
 }
 
 // SignResponse is returned immediately after receiving a sign request
@@ -30,6 +40,9 @@ type SignatureStatusResponse struct {
 // PublicKeyResponse contains the shared public key for the threshold signing system
 // This key can be used to verify any signatures produced by the system
 type PublicKeyResponse struct {
-	// PublicKey is the shared ECDSA public key (secp256k1) in compressed hex format
+	// PublicKey is the shared public key in compressed hex format, for
+	// whichever Scheme was requested
 	PublicKey string `json:"public_key"`
+	// Scheme identifies which signature scheme this key belongs to
+	Scheme string `json:"scheme,omitempty"`
 }
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"message-board/airgapframe"
+)
+
+// AirgapFrame is one QR-encodable chunk of a JSON-encoded NodeMessage; see
+// airgapframe.Frame. A cold signer's export-outgoing command (cmd/airgap-node)
+// splits a message into a sequence of these and renders each as a frame of
+// an animated QR code; a hot node's import-incoming command reverses the
+// process and posts the reassembled NodeMessages to POST /airgap/import
+// (handleAirgapImport), the same way handlePostMessage would. Only
+// NodeMessage.Payload crosses this boundary; the signing shares backing it
+// never leave the cold side.
+type AirgapFrame = airgapframe.Frame
+
+// ChunkNodeMessage JSON-encodes msg and splits it into AirgapFrames of at
+// most airgapframe.MaxFramePayload base64 bytes each, for tooling on the
+// board side that needs to re-derive the frames a cold signer would have
+// produced for msg (e.g. to verify an import against the original). The
+// cold signer's own export-outgoing command calls airgapframe.ChunkMessage
+// directly, since it never has a board connection to reach this function
+// through.
+func ChunkNodeMessage(msg NodeMessage) ([]AirgapFrame, error) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("encoding node message: %w", err)
+	}
+	return airgapframe.ChunkMessage(msg.ID, data), nil
+}
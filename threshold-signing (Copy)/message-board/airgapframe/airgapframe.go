@@ -0,0 +1,147 @@
+// Package airgapframe implements the QR-chunking and reassembly logic that
+// carries a single opaque message payload (in practice, a JSON-encoded
+// NodeMessage) across an airgap: a cold signer's export-outgoing command
+// splits the payload into Frames and renders each as a QR code; a hot
+// node's import-incoming command captures frames (camera or a directory of
+// PNGs) and reassembles them with a Reassembler. Only the payload bytes
+// cross the boundary; nothing here knows about NodeMessage, signing shares,
+// or the board, so it can be imported by both the board's airgap.go and the
+// cmd/airgap-node CLI without either depending on the other.
+package airgapframe
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// MaxFramePayload bounds the base64 payload each Frame carries, so a frame
+// (header plus payload) comfortably fits in a single QR code at a density
+// most phone or webcam readers can decode reliably.
+const MaxFramePayload = 1200
+
+// Frame is one QR-encodable chunk of a payload. ChunkMessage splits a
+// payload into a sequence of these (Seq 0..Total-1, sharing MsgID and
+// SHA256); Reassembler reverses the process once every Seq has been seen
+// and the reassembled bytes hash to SHA256.
+type Frame struct {
+	MsgID   string `json:"msg_id"`
+	Seq     int    `json:"seq"`
+	Total   int    `json:"total"`
+	SHA256  string `json:"sha256"`
+	Payload string `json:"payload"`
+}
+
+// ChunkMessage splits data into Frames of at most MaxFramePayload base64
+// bytes each, identified by msgID (the caller picks one unique to data,
+// e.g. the NodeMessage ID). The cold signer's export-outgoing command calls
+// this and renders each returned Frame as a QR code (e.g. via
+// github.com/skip2/go-qrcode); that rendering step lives in cmd/airgap-node,
+// not here.
+func ChunkMessage(msgID string, data []byte) []Frame {
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+	encoded := base64.StdEncoding.EncodeToString(data)
+
+	total := (len(encoded) + MaxFramePayload - 1) / MaxFramePayload
+	if total == 0 {
+		total = 1
+	}
+	frames := make([]Frame, 0, total)
+	for seq := 0; seq < total; seq++ {
+		start := seq * MaxFramePayload
+		end := start + MaxFramePayload
+		if end > len(encoded) {
+			end = len(encoded)
+		}
+		frames = append(frames, Frame{
+			MsgID:   msgID,
+			Seq:     seq,
+			Total:   total,
+			SHA256:  digest,
+			Payload: encoded[start:end],
+		})
+	}
+	return frames
+}
+
+// assembly tracks the frames seen so far for one in-flight MsgID.
+type assembly struct {
+	total  int
+	sha256 string
+	chunks map[int]string
+}
+
+// Reassembler accumulates Frames by MsgID until every Seq for a message has
+// been seen, then decodes the reassembled bytes and checks them against the
+// frames' shared SHA256 before handing back the original payload. A hot
+// node's import-incoming command feeds it frames decoded from a pluggable
+// frame source (a camera, or a directory of PNGs in a test harness); one
+// Reassembler is safe for concurrent use by multiple frame sources reading
+// the same animation for redundancy.
+type Reassembler struct {
+	mu   sync.Mutex
+	sets map[string]*assembly
+}
+
+// NewReassembler creates an empty Reassembler.
+func NewReassembler() *Reassembler {
+	return &Reassembler{sets: make(map[string]*assembly)}
+}
+
+// Add records frame and, once every Seq in [0, Total) for frame.MsgID has
+// been seen, reassembles and returns the original payload with ok set to
+// true. It returns ok == false while the message is still incomplete, and
+// an error if frame contradicts earlier frames for the same MsgID or the
+// reassembled bytes fail their SHA256 check.
+func (r *Reassembler) Add(frame Frame) (payload []byte, ok bool, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	set, exists := r.sets[frame.MsgID]
+	if !exists {
+		set = &assembly{total: frame.Total, sha256: frame.SHA256, chunks: make(map[int]string)}
+		r.sets[frame.MsgID] = set
+	}
+	if frame.Total != set.total || frame.SHA256 != set.sha256 {
+		return nil, false, fmt.Errorf("airgap frame for %s disagrees with earlier frames on total/sha256", frame.MsgID)
+	}
+	set.chunks[frame.Seq] = frame.Payload
+	if len(set.chunks) < set.total {
+		return nil, false, nil
+	}
+
+	var encoded string
+	for seq := 0; seq < set.total; seq++ {
+		chunk, have := set.chunks[seq]
+		if !have {
+			return nil, false, nil
+		}
+		encoded += chunk
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, false, fmt.Errorf("decoding reassembled airgap message %s: %w", frame.MsgID, err)
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != set.sha256 {
+		return nil, false, fmt.Errorf("reassembled airgap message %s fails sha256 check", frame.MsgID)
+	}
+
+	delete(r.sets, frame.MsgID)
+	return data, true, nil
+}
+
+// DecodeJSON is a convenience for reassembling into a known type, mirroring
+// the common case (v is a *NodeMessage) without making this package depend
+// on that type.
+func DecodeJSON(payload []byte, v interface{}) error {
+	if err := json.Unmarshal(payload, v); err != nil {
+		return fmt.Errorf("decoding reassembled airgap payload: %w", err)
+	}
+	return nil
+}
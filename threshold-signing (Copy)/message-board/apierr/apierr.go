@@ -0,0 +1,80 @@
+// Package apierr defines the typed, machine-readable errors MessageBoard
+// handlers return instead of calling http.Error with a free-form string,
+// so a node client can reliably distinguish "not found" from "conflict"
+// from "bad request" (see the render package, which turns one of these
+// into the JSON response actually sent over the wire).
+package apierr
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// Error is an HTTP API error: Status is the response code, Code is a
+// stable machine-readable string a client can switch on (e.g.
+// "presignature_request_not_found"), Message is safe to show the caller,
+// and Cause is the underlying error, logged server-side but never sent
+// over the wire. Stack is captured at construction time so a 500 can be
+// traced back to where it actually originated, not just where it was
+// rendered.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Cause   error
+	Stack   []byte
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Cause)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error { return e.Cause }
+
+// New constructs an Error with the given status, code, message and
+// cause, capturing the current stack. Handlers should generally use one
+// of the typed constructors below instead of calling this directly.
+func New(status int, code, message string, cause error) *Error {
+	return &Error{Status: status, Code: code, Message: message, Cause: cause, Stack: debug.Stack()}
+}
+
+// NotFound is a 404: the referenced resource does not exist.
+func NotFound(code, message string, cause error) *Error {
+	return New(http.StatusNotFound, code, message, cause)
+}
+
+// BadRequest is a 400: the request itself is malformed or missing a
+// required field.
+func BadRequest(code, message string, cause error) *Error {
+	return New(http.StatusBadRequest, code, message, cause)
+}
+
+// Conflict is a 409: the request is well-formed but conflicts with the
+// resource's current state, e.g. a round posted out of order against the
+// request's FSM state, or a public key already set to a different value.
+func Conflict(code, message string, cause error) *Error {
+	return New(http.StatusConflict, code, message, cause)
+}
+
+// Internal is a 500: an unexpected failure not caused by the client.
+func Internal(code, message string, cause error) *Error {
+	return New(http.StatusInternalServerError, code, message, cause)
+}
+
+// PreconditionFailed is a 412: a precondition the caller was expected to
+// satisfy first does not hold.
+func PreconditionFailed(code, message string, cause error) *Error {
+	return New(http.StatusPreconditionFailed, code, message, cause)
+}
+
+// Unauthorized is a 401: the request's credentials are missing or don't
+// verify. ErrUnauthorized (see keystore.go) already distinguishes this
+// failure mode from every other one in the codebase, so it gets its own
+// constructor alongside the five the taxonomy was designed around.
+func Unauthorized(code, message string, cause error) *Error {
+	return New(http.StatusUnauthorized, code, message, cause)
+}
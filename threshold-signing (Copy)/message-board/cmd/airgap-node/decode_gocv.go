@@ -0,0 +1,125 @@
+//go:build gocv
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gocv.io/x/gocv"
+)
+
+// frameSource yields the raw bytes decoded from each QR code it reads,
+// until there are none left (ok == false). camera and directory sources
+// both decode through gocv's QRCodeDetector, matching airgapframe's doc
+// comment that either a camera or a directory of PNGs may feed a
+// Reassembler.
+type frameSource interface {
+	next() (payload []byte, ok bool, err error)
+	Close() error
+}
+
+// newDirFrameSource reads every PNG in dir, in lexical order, decoding each
+// as a QR code. export-outgoing names frames "<msg_id>-<seq>-of-<total>.png"
+// with an unpadded seq, so past nine frames lexical order is not seq order;
+// the Reassembler keys frames by Seq, so the order does not affect
+// reassembly.
+func newDirFrameSource(dir string) (frameSource, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading frame directory: %w", err)
+	}
+	var paths []string
+	for _, e := range entries {
+		if !e.IsDir() && filepath.Ext(e.Name()) == ".png" {
+			paths = append(paths, filepath.Join(dir, e.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return &dirSource{paths: paths, detector: gocv.NewQRCodeDetector()}, nil
+}
+
+type dirSource struct {
+	paths    []string
+	pos      int
+	detector gocv.QRCodeDetector
+}
+
+func (d *dirSource) next() ([]byte, bool, error) {
+	if d.pos >= len(d.paths) {
+		return nil, false, nil
+	}
+	path := d.paths[d.pos]
+	d.pos++
+
+	img := gocv.IMRead(path, gocv.IMReadColor)
+	if img.Empty() {
+		return nil, false, fmt.Errorf("reading frame image %s", path)
+	}
+	defer img.Close()
+
+	payload, _, ok := decodeQR(&d.detector, img)
+	if !ok {
+		return nil, false, fmt.Errorf("no QR code found in %s", path)
+	}
+	return payload, true, nil
+}
+
+func (d *dirSource) Close() error { return d.detector.Close() }
+
+// newCameraFrameSource opens camera device index and decodes each frame it
+// captures as a QR code, skipping frames where none is found (the cold
+// signer's display may be mid-transition between frames).
+func newCameraFrameSource(index int) (frameSource, error) {
+	cap, err := gocv.OpenVideoCapture(index)
+	if err != nil {
+		return nil, fmt.Errorf("opening camera %d: %w", index, err)
+	}
+	return &cameraSource{cap: cap, detector: gocv.NewQRCodeDetector(), frame: gocv.NewMat()}, nil
+}
+
+type cameraSource struct {
+	cap      *gocv.VideoCapture
+	detector gocv.QRCodeDetector
+	frame    gocv.Mat
+}
+
+// next blocks reading camera frames until one decodes to a QR payload, the
+// camera is disconnected, or the caller stops calling it (the caller is
+// expected to apply its own timeout/cancellation around the loop it drives
+// this from).
+func (c *cameraSource) next() ([]byte, bool, error) {
+	for {
+		if ok := c.cap.Read(&c.frame); !ok {
+			return nil, false, fmt.Errorf("camera disconnected")
+		}
+		if c.frame.Empty() {
+			continue
+		}
+		if payload, _, ok := decodeQR(&c.detector, c.frame); ok {
+			return payload, true, nil
+		}
+	}
+}
+
+func (c *cameraSource) Close() error {
+	c.frame.Close()
+	c.detector.Close()
+	return c.cap.Close()
+}
+
+// decodeQR runs detector against img and reports the decoded string (if
+// any) as bytes, matching the QRCodeDetector.DetectAndDecode contract: an
+// empty result means no QR code was found in img, not that it decoded to
+// an empty string.
+func decodeQR(detector *gocv.QRCodeDetector, img gocv.Mat) (payload []byte, points gocv.Mat, ok bool) {
+	straight := gocv.NewMat()
+	defer straight.Close()
+	decoded, pts := detector.DetectAndDecode(img, &straight)
+	if decoded == "" {
+		return nil, pts, false
+	}
+	return []byte(decoded), pts, true
+}
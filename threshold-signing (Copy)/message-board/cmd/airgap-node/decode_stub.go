@@ -0,0 +1,26 @@
+//go:build !gocv
+
+package main
+
+import "fmt"
+
+// frameSource mirrors the gocv-backed implementation's interface so
+// import-incoming compiles either way; see decode_gocv.go.
+type frameSource interface {
+	next() (payload []byte, ok bool, err error)
+	Close() error
+}
+
+// errNoGocv is returned by both constructors below when airgap-node was
+// built without -tags gocv, so import-incoming fails with an actionable
+// message instead of the binary refusing to build at all (export-outgoing
+// needs no camera/decode support and should keep working either way).
+var errNoGocv = fmt.Errorf("built without QR decode support: rebuild with -tags gocv (requires OpenCV)")
+
+func newDirFrameSource(dir string) (frameSource, error) {
+	return nil, errNoGocv
+}
+
+func newCameraFrameSource(index int) (frameSource, error) {
+	return nil, errNoGocv
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	qrcode "github.com/skip2/go-qrcode"
+
+	"message-board/airgapframe"
+)
+
+// qrRecoveryLevel trades QR code density for error tolerance; Medium is
+// go-qrcode's suggested default for printed or screen-displayed codes that
+// a phone camera (rather than a flatbed scanner) will read back.
+const qrRecoveryLevel = qrcode.Medium
+
+// qrPixelSize is the rendered PNG's width and height in pixels, sized so
+// the frame is comfortably readable by a phone camera at arm's length
+// without the image becoming unwieldy to transfer.
+const qrPixelSize = 512
+
+// runExportOutgoing is the cold signer's export-outgoing command: it reads
+// one NodeMessage as JSON (from -in, or stdin if -in is "-"), chunks it
+// with airgapframe.ChunkMessage, and writes one QR-coded PNG per frame to
+// -out, named "<msg_id>-<seq>-of-<total>.png". Displaying those PNGs in
+// sequence (e.g. looping them on the cold machine's screen) is left to the
+// operator; this command only produces them.
+func runExportOutgoing(args []string) error {
+	fs := newFlagSet("export-outgoing")
+	in := fs.String("in", "-", "path to a NodeMessage JSON file, or - for stdin")
+	out := fs.String("out", ".", "directory to write QR frame PNGs to")
+	fs.Parse(args)
+
+	data, err := readInput(*in)
+	if err != nil {
+		return fmt.Errorf("reading node message: %w", err)
+	}
+
+	// Decode only the "id" field instead of message-board's NodeMessage
+	// type: this command never links against package main, so it only
+	// needs a stable ID to key the frames on, not the full shape. The input
+	// bytes themselves are chunked unchanged.
+	var msg struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return fmt.Errorf("decoding node message: %w", err)
+	}
+	if msg.ID == "" {
+		return fmt.Errorf("node message is missing its \"id\" field")
+	}
+
+	if err := os.MkdirAll(*out, 0o755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	frames := airgapframe.ChunkMessage(msg.ID, data)
+	for _, frame := range frames {
+		frameJSON, err := json.Marshal(frame)
+		if err != nil {
+			return fmt.Errorf("encoding frame %d/%d: %w", frame.Seq, frame.Total, err)
+		}
+		png, err := qrcode.Encode(string(frameJSON), qrRecoveryLevel, qrPixelSize)
+		if err != nil {
+			return fmt.Errorf("rendering frame %d/%d as QR: %w", frame.Seq, frame.Total, err)
+		}
+		path := filepath.Join(*out, fmt.Sprintf("%s-%d-of-%d.png", frame.MsgID, frame.Seq, frame.Total))
+		if err := os.WriteFile(path, png, 0o644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		fmt.Fprintln(os.Stdout, path)
+	}
+	return nil
+}
+
+// readInput reads all of path, or stdin if path is "-".
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"message-board/airgapframe"
+)
+
+// runImportIncoming is the hot node's import-incoming command: it captures
+// QR frames from -dir (a directory of PNGs, e.g. saved from a test
+// harness) or -camera (a live camera device index), feeds them to an
+// airgapframe.Reassembler, and writes the fully reassembled NodeMessages to
+// -out (or stdout), in the {"messages": [...]} shape POST /airgap/import
+// expects. Reading stops once every Seq for -count distinct messages has
+// been reassembled, or (dir only) once the source is exhausted first;
+// -camera requires -count since a live capture has no natural end.
+func runImportIncoming(args []string) error {
+	fs := newFlagSet("import-incoming")
+	dir := fs.String("dir", "", "directory of QR frame PNGs to import from")
+	camera := fs.Int("camera", -1, "camera device index to capture frames from")
+	count := fs.Int("count", 0, "stop after reassembling this many messages (required with -camera)")
+	out := fs.String("out", "-", "path to write the reassembled messages JSON to, or - for stdout")
+	fs.Parse(args)
+
+	if (*dir == "") == (*camera < 0) {
+		return fmt.Errorf("exactly one of -dir or -camera must be set")
+	}
+	if *camera >= 0 && *count <= 0 {
+		return fmt.Errorf("-camera requires -count: a live capture has no natural end")
+	}
+
+	var source frameSource
+	var err error
+	if *dir != "" {
+		source, err = newDirFrameSource(*dir)
+	} else {
+		source, err = newCameraFrameSource(*camera)
+	}
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	reassembler := airgapframe.NewReassembler()
+	var messages []json.RawMessage
+	// completed holds the MsgIDs already reassembled, so a frame captured
+	// again after its message completed is not counted a second time.
+	completed := make(map[string]bool)
+	for *count <= 0 || len(messages) < *count {
+		payload, ok, err := source.next()
+		if err != nil {
+			return fmt.Errorf("reading frame: %w", err)
+		}
+		if !ok {
+			break
+		}
+
+		var frame airgapframe.Frame
+		if err := json.Unmarshal(payload, &frame); err != nil {
+			return fmt.Errorf("decoding frame: %w", err)
+		}
+		if completed[frame.MsgID] {
+			continue
+		}
+		msg, done, err := reassembler.Add(frame)
+		if err != nil {
+			return fmt.Errorf("reassembling frame %d/%d for %s: %w", frame.Seq, frame.Total, frame.MsgID, err)
+		}
+		if done {
+			completed[frame.MsgID] = true
+			messages = append(messages, json.RawMessage(msg))
+		}
+	}
+
+	body, err := json.Marshal(map[string][]json.RawMessage{"messages": messages})
+	if err != nil {
+		return fmt.Errorf("encoding reassembled messages: %w", err)
+	}
+	return writeOutput(*out, body)
+}
+
+func writeOutput(path string, data []byte) error {
+	data = append(data, '\n')
+	if path == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
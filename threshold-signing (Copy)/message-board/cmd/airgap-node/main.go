@@ -0,0 +1,51 @@
+// Command airgap-node is the cold/hot node binary for the airgapped
+// signer described in airgap.go: export-outgoing chunks a NodeMessage into
+// QR frames for a cold signer to display, and import-incoming captures
+// those frames back into the NodeMessages a hot node posts to
+// POST /airgap/import. Building with -tags gocv links OpenCV for camera
+// capture and QR decoding; without that tag, export-outgoing still works
+// (it only needs the pure-Go go-qrcode encoder) but import-incoming fails
+// with a message telling the operator to rebuild with the tag.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "export-outgoing":
+		err = runExportOutgoing(os.Args[2:])
+	case "import-incoming":
+		err = runImportIncoming(os.Args[2:])
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "airgap-node: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: airgap-node <export-outgoing|import-incoming> [flags]")
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: airgap-node %s [flags]\n", name)
+		fs.PrintDefaults()
+	}
+	return fs
+}
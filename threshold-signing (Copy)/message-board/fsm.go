@@ -0,0 +1,101 @@
+package main
+
+import "fmt"
+
+// State identifies where a SigningRequest or PresignatureRequest is in its
+// protocol lifecycle. PostMessage and PostPresignatureMessage reject any
+// message whose Round does not match the state's expected round for the
+// sender, closing the silent-desync gap where a lagging node's stale-round
+// payload gets picked up by a later quorum computation.
+type State string
+
+const (
+	StateAwaitingParticipants State = "awaiting_participants"
+	StateDKGCommit            State = "dkg_commit"
+	StateDKGDeal              State = "dkg_deal"
+	StateDKGResponse          State = "dkg_response"
+	StatePresignRound1        State = "presign_round1"
+	StatePresignRound2        State = "presign_round2"
+	StatePresignRound3        State = "presign_round3"
+	StateSignPartial          State = "sign_partial"
+	StateCompleted            State = "completed"
+	StateFailed               State = "failed"
+)
+
+// defaultQuorumSize is the minimum number of distinct FromNode senders
+// required for a given round before the FSM advances to the next state. It
+// matches the minimal t+1 threshold of the smallest supported deployment;
+// roundQuorum raises this automatically for any deployment that has
+// registered more nodes than this, so operators of a larger cohort only
+// need to construct the Store with NewStoreWithQuorum if they want a floor
+// stricter than "every registered node must post".
+const defaultQuorumSize = 2
+
+// dkgRounds orders the DKG states PostMessage drives a SigningRequest
+// through; index 0 is round 1.
+var dkgRounds = []State{StateDKGCommit, StateDKGDeal, StateDKGResponse}
+
+// presignRounds orders the states PostPresignatureMessage drives a
+// PresignatureRequest through; index 0 is round 1.
+var presignRounds = []State{StatePresignRound1, StatePresignRound2, StatePresignRound3}
+
+// advanceRoundState checks that round matches the expected round for state,
+// then reports whether quorum (distinct senders) has now been reached for
+// that round. It does not mutate anything; callers apply the transition.
+func advanceRoundState(state State, expectedRound, round int, distinctSenders int, quorumSize int) (quorumReached bool, err error) {
+	if round != expectedRound {
+		return false, fmt.Errorf("message round %d does not match expected round %d for state %q", round, expectedRound, state)
+	}
+	return distinctSenders >= quorumSize, nil
+}
+
+// nextDKGState returns the state/round to move to once quorum is reached
+// for round (1-indexed), and whether the DKG sequence is now complete.
+func nextDKGState(round int) (next State, expectedRound int, done bool) {
+	if round >= len(dkgRounds) {
+		return StateSignPartial, 0, true
+	}
+	return dkgRounds[round], round + 1, false
+}
+
+// nextPresignState returns the state/round to move to once quorum is
+// reached for round (1-indexed), and whether presigning is now complete.
+func nextPresignState(round int) (next State, expectedRound int, done bool) {
+	if round >= len(presignRounds) {
+		return StateCompleted, 0, true
+	}
+	return presignRounds[round], round + 1, false
+}
+
+// roundQuorum returns the number of distinct senders applyMessage and
+// applyPresignatureMessage require before advancing a round: s.quorumSize,
+// or the number of registered nodes if that is larger. Registered nodes are
+// the best available stand-in for "every expected participant" (the FSM
+// has no separate concept of a request's participant set), so a deployment
+// that registers more nodes than s.quorumSize doesn't have the round
+// advancing before every node has actually posted.
+func (s *Store) roundQuorum() int {
+	if registered := s.nodeKeys.Count(); registered > s.quorumSize {
+		return registered
+	}
+	return s.quorumSize
+}
+
+// distinctFromNodes counts the distinct FromNode values among msgs whose
+// Round matches round.
+func distinctFromNodes(msgs []*NodeMessage, round int) int {
+	seen := make(map[string]struct{})
+	for _, m := range msgs {
+		if m.Round == round {
+			seen[m.FromNode] = struct{}{}
+		}
+	}
+	return len(seen)
+}
+
+// RequestState is the response shape for GET /requests/{id}/state.
+type RequestState struct {
+	RequestID     string `json:"request_id"`
+	State         string `json:"state"`
+	ExpectedRound int    `json:"expected_round"`
+}
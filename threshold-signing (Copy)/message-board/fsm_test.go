@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"testing"
+)
+
+// TestAdvanceRoundStateRejectsWrongRound covers the desync guard
+// advanceRoundState exists for: a message posted against a round other
+// than the one state currently expects must be rejected, not silently
+// counted toward quorum for the wrong round.
+func TestAdvanceRoundStateRejectsWrongRound(t *testing.T) {
+	if _, err := advanceRoundState(StateDKGCommit, 1, 2, 2, 2); err == nil {
+		t.Fatal("advanceRoundState accepted round 2 against expected round 1, want error")
+	}
+}
+
+// TestAdvanceRoundStateQuorum covers the two outcomes once a message's
+// round is accepted: quorum reached vs. still waiting on more distinct
+// senders.
+func TestAdvanceRoundStateQuorum(t *testing.T) {
+	cases := []struct {
+		name            string
+		distinctSenders int
+		quorumSize      int
+		wantReached     bool
+	}{
+		{"below quorum", 1, 2, false},
+		{"at quorum", 2, 2, true},
+		{"above quorum", 3, 2, true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			reached, err := advanceRoundState(StateDKGCommit, 1, 1, c.distinctSenders, c.quorumSize)
+			if err != nil {
+				t.Fatalf("advanceRoundState: %v", err)
+			}
+			if reached != c.wantReached {
+				t.Errorf("quorumReached = %v, want %v", reached, c.wantReached)
+			}
+		})
+	}
+}
+
+// TestNextDKGState walks the full dkgRounds sequence, confirming each
+// completed round (1-indexed) hands off to the next state in order and
+// that completing the last round reports the DKG sequence done and moves
+// on to signing.
+func TestNextDKGState(t *testing.T) {
+	next, expectedRound, done := nextDKGState(1)
+	if next != StateDKGDeal || expectedRound != 2 || done {
+		t.Fatalf("nextDKGState(1) = (%q, %d, %v), want (%q, 2, false)", next, expectedRound, done, StateDKGDeal)
+	}
+
+	next, expectedRound, done = nextDKGState(2)
+	if next != StateDKGResponse || expectedRound != 3 || done {
+		t.Fatalf("nextDKGState(2) = (%q, %d, %v), want (%q, 3, false)", next, expectedRound, done, StateDKGResponse)
+	}
+
+	next, _, done = nextDKGState(3)
+	if next != StateSignPartial || !done {
+		t.Fatalf("nextDKGState(3) = (%q, done=%v), want (%q, done=true)", next, done, StateSignPartial)
+	}
+}
+
+// TestNextPresignState mirrors TestNextDKGState for the presign sequence.
+func TestNextPresignState(t *testing.T) {
+	next, expectedRound, done := nextPresignState(1)
+	if next != StatePresignRound2 || expectedRound != 2 || done {
+		t.Fatalf("nextPresignState(1) = (%q, %d, %v), want (%q, 2, false)", next, expectedRound, done, StatePresignRound2)
+	}
+
+	next, _, done = nextPresignState(3)
+	if next != StateCompleted || !done {
+		t.Fatalf("nextPresignState(3) = (%q, done=%v), want (%q, done=true)", next, done, StateCompleted)
+	}
+}
+
+// TestRoundQuorumScalesToRegisteredNodes covers the regression this series
+// fixed (chunk0-2's original commit): roundQuorum must rise to match the
+// registered node count once it exceeds the configured quorumSize, not
+// stay pinned at quorumSize forever.
+func TestRoundQuorumScalesToRegisteredNodes(t *testing.T) {
+	s, err := NewStoreWithLog(2, NewInMemoryLog(), nil)
+	if err != nil {
+		t.Fatalf("NewStoreWithLog: %v", err)
+	}
+
+	if got := s.roundQuorum(); got != 2 {
+		t.Fatalf("roundQuorum() with no registered nodes = %d, want 2 (quorumSize)", got)
+	}
+
+	for _, nodeID := range []string{"node-1", "node-2", "node-3"} {
+		pub, _, err := ed25519.GenerateKey(nil)
+		if err != nil {
+			t.Fatalf("generating key for %s: %v", nodeID, err)
+		}
+		if err := s.RegisterNode(context.Background(), nodeID, pub); err != nil {
+			t.Fatalf("RegisterNode(%s): %v", nodeID, err)
+		}
+	}
+
+	if got := s.roundQuorum(); got != 3 {
+		t.Fatalf("roundQuorum() with 3 registered nodes = %d, want 3", got)
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"message-board/apierr"
+	"message-board/render"
+)
+
+// requestDeadline bounds how long a single request's context is valid for.
+// Store methods check ctx.Err() before acquiring their lock, so a request
+// that is already past its deadline when it reaches a Store call is
+// rejected instead of proceeding; once inside, the call runs to completion
+// regardless of the deadline, since the underlying work (e.g. a durable
+// FileLog.Append doing a blocking fsync) does not take a context. A single
+// slow Store call can therefore still tie up a node's round past
+// requestDeadline — this bounds queueing time, not execution time.
+// Configurable via MPC_BOARD_DEADLINE_MS.
+var requestDeadline = 5 * time.Second
+
+// slowRequestThreshold is the latency above which a completed request is
+// logged as slow, a tripwire for operators rather than a request-shaping
+// parameter, so it is not independently configurable.
+const slowRequestThreshold = 1 * time.Second
+
+// requestsTotal and requestLatency give operators the same observability
+// surface any production coordination service needs: which endpoints are
+// being hit, at what rate, and how long they take, broken down by the
+// status code actually written.
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "mpc_board_requests_total",
+		Help: "Total number of MessageBoard HTTP requests, labeled by endpoint and status code.",
+	}, []string{"endpoint", "status"})
+
+	requestLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "mpc_board_request_latency_seconds",
+		Help:    "MessageBoard HTTP request latency in seconds, labeled by endpoint and status code.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"endpoint", "status"})
+)
+
+// statusRecorder wraps an http.ResponseWriter to capture the status code a
+// handler wrote, since http.ResponseWriter doesn't expose it back to the
+// caller and Handler.ServeHTTP needs it for metrics. Defaults to 200, the
+// status net/http assumes if a handler never calls WriteHeader.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// HandlerFunc is the signature every Handler.Func must have: like
+// http.HandlerFunc it writes a successful response directly, but returns
+// an error instead of writing one itself, so Handler.ServeHTTP can render
+// it through the shared apierr/render taxonomy uniformly across every
+// endpoint.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Handler is a named, method-restricted HTTP endpoint, similar in spirit to
+// the sigsum log-go handler pattern. Registering endpoints as Handlers
+// instead of bare http.HandleFunc calls gives every one of them a uniform
+// 405 for the wrong method, a per-request deadline threaded onto r's
+// context, panic recovery, and Prometheus instrumentation, instead of each
+// handler having to do its own bookkeeping. Method may be left empty for
+// an endpoint that dispatches more than one method itself (e.g. GET+POST
+// on the same path); such a handler is responsible for its own 405s.
+type Handler struct {
+	Endpoint string
+	Method   string
+	Func     HandlerFunc
+	// NoDeadline exempts this Handler from requestDeadline. Set it on
+	// endpoints that dispatch to an SSE stream (handleMessageStream,
+	// handlePresignatureMessageStream, handleStatusStream,
+	// handlePartialSignatureStream), which are designed to hold the
+	// connection open for up to streamTimeout — a long-poll, not a slow
+	// request.
+	NoDeadline bool
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.Method != "" && r.Method != h.Method {
+		render.Error(w, r, apierr.New(http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", nil))
+		requestsTotal.WithLabelValues(h.Endpoint, strconv.Itoa(http.StatusMethodNotAllowed)).Inc()
+		return
+	}
+
+	if !h.NoDeadline {
+		ctx, cancel := context.WithDeadline(r.Context(), time.Now().Add(requestDeadline))
+		defer cancel()
+		r = r.WithContext(ctx)
+	}
+
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	start := time.Now()
+	if err := h.call(rec, r); err != nil {
+		render.Error(rec, r, err)
+	}
+	elapsed := time.Since(start)
+
+	status := strconv.Itoa(rec.status)
+	requestsTotal.WithLabelValues(h.Endpoint, status).Inc()
+	requestLatency.WithLabelValues(h.Endpoint, status).Observe(elapsed.Seconds())
+
+	if elapsed > slowRequestThreshold {
+		log.Printf("slow request: %s %s took %s (status %d)", r.Method, h.Endpoint, elapsed, rec.status)
+	}
+}
+
+// call invokes h.Func, recovering a panic into an *apierr.Error instead of
+// crashing the process, so a bug in one handler can't take the whole
+// board down and still surfaces through the same renderer as any other
+// handler error.
+func (h Handler) call(w http.ResponseWriter, r *http.Request) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = apierr.Internal("panic", fmt.Sprintf("panic in %s: %v", h.Endpoint, p), fmt.Errorf("%v", p))
+		}
+	}()
+	return h.Func(w, r)
+}
+
+// withRequestDeadline applies requestDeadline to r's context, returning a
+// request bound to it and the matching cancel func. It exists for
+// dispatcher handlers (e.g. handleRequestByID) registered NoDeadline
+// because some of the routes they dispatch to are SSE streams, but whose
+// other, non-streaming branches still need the bound Handler.ServeHTTP
+// would otherwise have applied; the caller applies it only to those
+// branches instead of the whole prefix.
+func withRequestDeadline(r *http.Request) (*http.Request, context.CancelFunc) {
+	ctx, cancel := context.WithDeadline(r.Context(), time.Now().Add(requestDeadline))
+	return r.WithContext(ctx), cancel
+}
+
+// configureRequestDeadline overrides requestDeadline from MPC_BOARD_DEADLINE_MS
+// (milliseconds) if set, otherwise leaves the default in place.
+func configureRequestDeadline() {
+	raw := os.Getenv("MPC_BOARD_DEADLINE_MS")
+	if raw == "" {
+		return
+	}
+	parsed, err := strconv.Atoi(raw)
+	if err != nil || parsed < 1 {
+		log.Fatalf("invalid MPC_BOARD_DEADLINE_MS %q: %v", raw, err)
+	}
+	requestDeadline = time.Duration(parsed) * time.Millisecond
+}
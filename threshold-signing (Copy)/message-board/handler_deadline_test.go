@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHandleRequestByIDAppliesDeadlineToNonStreamMethods guards against the
+// bug a maintainer flagged: requests_by_id is registered NoDeadline so its
+// messages/stream and status/stream sub-routes can hold the connection
+// open past requestDeadline, but that must not leave the plain GET/PUT
+// branches unbounded too. A PUT against an already-expired requestDeadline
+// must still fail with context.DeadlineExceeded, proving
+// withRequestDeadline is actually applied inside handleRequestByID rather
+// than only at the (disabled) Handler.ServeHTTP layer.
+func TestHandleRequestByIDAppliesDeadlineToNonStreamMethods(t *testing.T) {
+	origDeadline := requestDeadline
+	origStore := store
+	defer func() {
+		requestDeadline = origDeadline
+		store = origStore
+	}()
+
+	var err error
+	store, err = NewStoreWithLog(1, NewInMemoryLog(), nil)
+	if err != nil {
+		t.Fatalf("NewStoreWithLog: %v", err)
+	}
+
+	req, err := store.CreateRequest(context.Background(), "msg", "", "")
+	if err != nil {
+		t.Fatalf("CreateRequest: %v", err)
+	}
+
+	requestDeadline = time.Nanosecond
+	time.Sleep(time.Millisecond)
+
+	r := httptest.NewRequest(http.MethodPut, "/requests/"+req.ID, strings.NewReader(`{"status":"cancelled"}`))
+	w := httptest.NewRecorder()
+
+	if err := handleRequestByID(w, r); err == nil {
+		t.Fatal("handleRequestByID succeeded past an expired requestDeadline, want context.DeadlineExceeded")
+	} else if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
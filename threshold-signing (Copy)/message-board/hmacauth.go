@@ -0,0 +1,406 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hmacAlgorithm is the Authorization scheme every mutating request must
+// use, modeled on AWS SigV4 but scoped to this board: a daily key derived
+// from a node's secret signs a canonicalized request, rather than nodes
+// sharing one long-lived MAC key directly.
+const hmacAlgorithm = "MPC-HMAC-SHA256"
+
+// hmacMaxClockSkew bounds how far a request's X-Mpc-Date header may drift
+// from the board's clock before it is rejected, limiting how long a
+// captured-but-not-yet-replayed request stays valid.
+const hmacMaxClockSkew = 5 * time.Minute
+
+// hmacDateLayout is the yyyymmdd date used both in the Authorization
+// header's Credential scope and to derive the day's signing key.
+const hmacDateLayout = "20060102"
+
+// hmacSignedHeaders is the fixed, ordered set of headers every signed
+// request must include; it matches the SignedHeaders list required in the
+// Authorization header.
+var hmacSignedHeaders = []string{"host", "x-mpc-date", "x-mpc-node"}
+
+// defaultNonceCacheSize bounds the replay cache across all nodes. It only
+// needs to cover hmacMaxClockSkew worth of traffic, since a signature older
+// than that is already rejected on its timestamp regardless of whether it
+// is still in the cache.
+const defaultNonceCacheSize = 10000
+
+// NodeCredential is the access_key_id/secret_key pair a node is provisioned
+// with out of band (see main's MPC_BOARD_CREDENTIALS_PATH), used to sign
+// every mutating HTTP request. Unlike the ed25519 key in KeyStore, which
+// authenticates one NodeMessage's contents, this authenticates the HTTP
+// request that carries it.
+type NodeCredential struct {
+	NodeID      string `json:"node_id"`
+	AccessKeyID string `json:"access_key_id"`
+	SecretKey   string `json:"secret_key"`
+}
+
+// CredentialStore holds the NodeCredential each node has been provisioned
+// with, keyed by AccessKeyID so the Authorization header's Credential field
+// can look one up directly.
+type CredentialStore struct {
+	mu    sync.RWMutex
+	byKey map[string]NodeCredential
+}
+
+// NewCredentialStore creates an empty CredentialStore.
+func NewCredentialStore() *CredentialStore {
+	return &CredentialStore{byKey: make(map[string]NodeCredential)}
+}
+
+// Provision records cred, keyed by its AccessKeyID. Re-provisioning the same
+// AccessKeyID with a different NodeID or SecretKey is rejected, so a node's
+// credential can't be silently reassigned to someone else.
+func (cs *CredentialStore) Provision(cred NodeCredential) error {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+
+	if existing, ok := cs.byKey[cred.AccessKeyID]; ok && existing != cred {
+		return fmt.Errorf("access key %s is already provisioned with different credentials", cred.AccessKeyID)
+	}
+	cs.byKey[cred.AccessKeyID] = cred
+	return nil
+}
+
+// Lookup returns the NodeCredential provisioned for accessKeyID, or
+// ok == false if none was.
+func (cs *CredentialStore) Lookup(accessKeyID string) (cred NodeCredential, ok bool) {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	cred, ok = cs.byKey[accessKeyID]
+	return cred, ok
+}
+
+// nonceCache rejects replays of an already-seen (access key, date,
+// signature) triple. It is a bounded LRU: once full, the oldest entry is
+// evicted to make room for the newest, which is safe because
+// hmacMaxClockSkew already bounds how long a signature is accepted
+// regardless of whether it is still resident in the cache.
+type nonceCache struct {
+	mu    sync.Mutex
+	max   int
+	seen  map[string]struct{}
+	order []string
+}
+
+// newNonceCache creates a nonceCache holding at most max entries.
+func newNonceCache(max int) *nonceCache {
+	return &nonceCache{max: max, seen: make(map[string]struct{}, max)}
+}
+
+// checkAndRemember returns false if key has already been seen (a replay);
+// otherwise it records key and returns true.
+func (c *nonceCache) checkAndRemember(key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.seen[key]; ok {
+		return false
+	}
+	if len(c.order) >= c.max {
+		oldest := c.order[0]
+		c.order = c.order[1:]
+		delete(c.seen, oldest)
+	}
+	c.seen[key] = struct{}{}
+	c.order = append(c.order, key)
+	return true
+}
+
+// canonicalRequest builds the SigV4-style string that is actually signed:
+// the method, path, sorted-and-encoded query string, the signed headers (in
+// hmacSignedHeaders order) each as "name:value\n", the semicolon-joined list
+// of signed header names, and the hex-encoded SHA256 of the body.
+func canonicalRequest(method, path string, query url.Values, headers http.Header, body []byte) string {
+	var canonicalQuery string
+	if len(query) > 0 {
+		keys := make([]string, 0, len(query))
+		for k := range query {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		parts := make([]string, 0, len(query))
+		for _, k := range keys {
+			values := append([]string(nil), query[k]...)
+			sort.Strings(values)
+			for _, v := range values {
+				parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+			}
+		}
+		canonicalQuery = strings.Join(parts, "&")
+	}
+
+	var canonicalHeaders strings.Builder
+	for _, h := range hmacSignedHeaders {
+		canonicalHeaders.WriteString(h)
+		canonicalHeaders.WriteByte(':')
+		canonicalHeaders.WriteString(strings.TrimSpace(headers.Get(h)))
+		canonicalHeaders.WriteByte('\n')
+	}
+
+	bodyHash := sha256.Sum256(body)
+
+	return strings.Join([]string{
+		method,
+		path,
+		canonicalQuery,
+		canonicalHeaders.String(),
+		strings.Join(hmacSignedHeaders, ";"),
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")
+}
+
+// canonicalRequestHeaders rebuilds the Host header verifyNodeHMAC needs to
+// pass to canonicalRequest. Go's net/http strips an incoming request's Host
+// header out of r.Header (it's only exposed via r.Host), while a client
+// signs over an explicit, non-empty Host via signRequest/HMACClient.Do — so
+// canonicalRequest must be given r.Host (or X-Forwarded-Host, if the board
+// is behind a reverse proxy that sets it) rather than r.Header directly, or
+// every legitimately signed request fails verification.
+func canonicalRequestHeaders(r *http.Request) http.Header {
+	host := r.Host
+	if fwd := r.Header.Get("X-Forwarded-Host"); fwd != "" {
+		host = fwd
+	}
+
+	headers := r.Header.Clone()
+	headers.Set("Host", host)
+	return headers
+}
+
+// deriveSigningKey derives the day's signing key for secret and date
+// (formatted per hmacDateLayout): HMAC(secret, date) -> HMAC(_, "mpc-board").
+// Scoping the derived key to a single day bounds how long it remains useful
+// if it (as opposed to the long-lived secret) ever leaked.
+func deriveSigningKey(secret, date string) []byte {
+	dateKey := hmacSum([]byte(secret), []byte(date))
+	return hmacSum(dateKey, []byte("mpc-board"))
+}
+
+func hmacSum(key, data []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// signRequest computes the Authorization header value a node sends when
+// calling the board as cred, for a request with the given method, path,
+// query, Host header, X-Mpc-Date header (RFC3339), and X-Mpc-Node header. It
+// is a plain package-level function, rather than only living inside
+// HMACClient, so tests can construct a valid header without going through
+// an *http.Request or HTTP round trip.
+func signRequest(cred NodeCredential, method, path string, query url.Values, host, xMpcDate, xMpcNode string, body []byte) (string, error) {
+	t, err := time.Parse(time.RFC3339, xMpcDate)
+	if err != nil {
+		return "", fmt.Errorf("x-mpc-date must be RFC3339: %w", err)
+	}
+	date := t.UTC().Format(hmacDateLayout)
+
+	headers := http.Header{}
+	headers.Set("Host", host)
+	headers.Set("X-Mpc-Date", xMpcDate)
+	headers.Set("X-Mpc-Node", xMpcNode)
+
+	canonical := canonicalRequest(method, path, query, headers, body)
+	signingKey := deriveSigningKey(cred.SecretKey, date)
+	signature := hex.EncodeToString(hmacSum(signingKey, []byte(canonical)))
+
+	return fmt.Sprintf("%s Credential=%s/%s/mpc-board, SignedHeaders=%s, Signature=%s",
+		hmacAlgorithm, cred.AccessKeyID, date, strings.Join(hmacSignedHeaders, ";"), signature), nil
+}
+
+// parsedAuthorization is an Authorization header's fields, extracted but not
+// yet verified against a credential.
+type parsedAuthorization struct {
+	accessKeyID   string
+	date          string
+	signedHeaders []string
+	signature     string
+}
+
+// parseAuthorizationHeader parses header, expecting the form:
+// "MPC-HMAC-SHA256 Credential=<id>/<yyyymmdd>/mpc-board, SignedHeaders=host;x-mpc-date;x-mpc-node, Signature=<hex>"
+func parseAuthorizationHeader(header string) (*parsedAuthorization, error) {
+	const prefix = hmacAlgorithm + " "
+	if !strings.HasPrefix(header, prefix) {
+		return nil, fmt.Errorf("%w: unsupported or missing Authorization scheme", ErrUnauthorized)
+	}
+
+	fields := make(map[string]string)
+	for _, part := range strings.Split(strings.TrimPrefix(header, prefix), ",") {
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("%w: malformed Authorization header", ErrUnauthorized)
+		}
+		fields[kv[0]] = kv[1]
+	}
+
+	credParts := strings.Split(fields["Credential"], "/")
+	if len(credParts) != 3 || credParts[2] != "mpc-board" {
+		return nil, fmt.Errorf("%w: malformed Credential scope", ErrUnauthorized)
+	}
+	signedHeaders := fields["SignedHeaders"]
+	signature := fields["Signature"]
+	if signedHeaders == "" || signature == "" {
+		return nil, fmt.Errorf("%w: missing SignedHeaders or Signature", ErrUnauthorized)
+	}
+
+	return &parsedAuthorization{
+		accessKeyID:   credParts[0],
+		date:          credParts[1],
+		signedHeaders: strings.Split(signedHeaders, ";"),
+		signature:     signature,
+	}, nil
+}
+
+// verifyNodeHMAC checks r's Authorization header against creds, rejecting
+// it if: the header is missing or malformed; its SignedHeaders don't match
+// hmacSignedHeaders exactly; its X-Mpc-Date is missing, unparseable, or
+// skewed from now by more than hmacMaxClockSkew; the recomputed signature
+// doesn't match; the (access key, date, signature) triple has already been
+// seen (a replay); or, when checkFromNode is true, the JSON body's
+// "from_node" field doesn't match the credential's NodeID. On success it
+// restores r.Body so the handler can still decode it, and returns the
+// credential's NodeID so callers that bind a different field (e.g. the
+// node_id being registered, rather than from_node) can check it themselves.
+func verifyNodeHMAC(creds *CredentialStore, nonces *nonceCache, r *http.Request, checkFromNode bool) (string, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", fmt.Errorf("%w: missing Authorization header", ErrUnauthorized)
+	}
+	auth, err := parseAuthorizationHeader(header)
+	if err != nil {
+		return "", err
+	}
+	if strings.Join(auth.signedHeaders, ";") != strings.Join(hmacSignedHeaders, ";") {
+		return "", fmt.Errorf("%w: SignedHeaders must be %s", ErrUnauthorized, strings.Join(hmacSignedHeaders, ";"))
+	}
+
+	cred, ok := creds.Lookup(auth.accessKeyID)
+	if !ok {
+		return "", fmt.Errorf("%w: unknown access key %s", ErrUnauthorized, auth.accessKeyID)
+	}
+
+	xMpcDate := r.Header.Get("X-Mpc-Date")
+	requestTime, err := time.Parse(time.RFC3339, xMpcDate)
+	if err != nil {
+		return "", fmt.Errorf("%w: missing or malformed X-Mpc-Date header", ErrUnauthorized)
+	}
+	if skew := time.Since(requestTime); skew > hmacMaxClockSkew || skew < -hmacMaxClockSkew {
+		return "", fmt.Errorf("%w: X-Mpc-Date is skewed by %s", ErrUnauthorized, skew)
+	}
+	if requestTime.UTC().Format(hmacDateLayout) != auth.date {
+		return "", fmt.Errorf("%w: Credential date does not match X-Mpc-Date", ErrUnauthorized)
+	}
+
+	xMpcNode := r.Header.Get("X-Mpc-Node")
+	if xMpcNode != cred.NodeID {
+		return "", fmt.Errorf("%w: X-Mpc-Node does not match the credential's node", ErrUnauthorized)
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", fmt.Errorf("%w: reading request body: %v", ErrUnauthorized, err)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	if checkFromNode {
+		var parsed struct {
+			FromNode string `json:"from_node"`
+		}
+		if err := json.Unmarshal(body, &parsed); err != nil {
+			return "", fmt.Errorf("%w: decoding body to check from_node: %v", ErrUnauthorized, err)
+		}
+		if parsed.FromNode != cred.NodeID {
+			return "", fmt.Errorf("%w: from_node does not match the credential's node", ErrUnauthorized)
+		}
+	}
+
+	canonical := canonicalRequest(r.Method, r.URL.Path, r.URL.Query(), canonicalRequestHeaders(r), body)
+	signingKey := deriveSigningKey(cred.SecretKey, auth.date)
+	expected := hmacSum(signingKey, []byte(canonical))
+	got, err := hex.DecodeString(auth.signature)
+	if err != nil || subtle.ConstantTimeCompare(expected, got) != 1 {
+		return "", fmt.Errorf("%w: signature does not verify", ErrUnauthorized)
+	}
+
+	nonceKey := auth.accessKeyID + "/" + auth.date + "/" + auth.signature
+	if !nonces.checkAndRemember(nonceKey) {
+		return "", fmt.Errorf("%w: request already seen (replay)", ErrUnauthorized)
+	}
+
+	return cred.NodeID, nil
+}
+
+// HMACClient signs outgoing requests as cred and sends them to baseURL, so a
+// node's existing HTTP call sites only change at construction time: replace
+// a bare *http.Client with an HMACClient built from the node's provisioned
+// NodeCredential.
+type HMACClient struct {
+	cred       NodeCredential
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHMACClient creates an HMACClient that signs requests as cred and sends
+// them to baseURL using httpClient (or http.DefaultClient if nil).
+func NewHMACClient(cred NodeCredential, baseURL string, httpClient *http.Client) *HMACClient {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &HMACClient{cred: cred, baseURL: strings.TrimRight(baseURL, "/"), httpClient: httpClient}
+}
+
+// Do sends an HTTP request for method and path (e.g. "/messages"), with
+// query appended and body sent as the request body, signed with the
+// client's credential under the current time.
+func (c *HMACClient) Do(method, path string, query url.Values, body []byte) (*http.Response, error) {
+	u := c.baseURL + path
+	if encoded := query.Encode(); encoded != "" {
+		u += "?" + encoded
+	}
+
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("building request: %w", err)
+	}
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	xMpcDate := time.Now().UTC().Format(time.RFC3339)
+
+	req.Header.Set("Host", host)
+	req.Header.Set("X-Mpc-Date", xMpcDate)
+	req.Header.Set("X-Mpc-Node", c.cred.NodeID)
+	req.Header.Set("Content-Type", "application/json")
+
+	authHeader, err := signRequest(c.cred, method, req.URL.Path, query, host, xMpcDate, c.cred.NodeID, body)
+	if err != nil {
+		return nil, fmt.Errorf("signing request: %w", err)
+	}
+	req.Header.Set("Authorization", authHeader)
+
+	return c.httpClient.Do(req)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestHMACRoundTrip drives a real HMACClient against a real net/http
+// server (rather than calling canonicalRequest/verifyNodeHMAC directly),
+// so it would have caught the bug where verifyNodeHMAC canonicalized
+// r.Header.Get("Host") — which net/http always leaves empty on the
+// server, since it strips an incoming request's Host header out of
+// r.Header and exposes it only via r.Host — while the client signed over
+// an explicit, non-empty Host. That mismatch failed every legitimately
+// signed request.
+func TestHMACRoundTrip(t *testing.T) {
+	cred := NodeCredential{NodeID: "node-1", AccessKeyID: "ak-1", SecretKey: "secret-1"}
+	creds := NewCredentialStore()
+	if err := creds.Provision(cred); err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	nonces := newNonceCache(defaultNonceCacheSize)
+
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, verifyErr = verifyNodeHMAC(creds, nonces, r, false)
+		if verifyErr != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewHMACClient(cred, server.URL, nil)
+	resp, err := client.Do(http.MethodPost, "/messages", nil, []byte(`{"from_node":"node-1"}`))
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	defer resp.Body.Close()
+	io.ReadAll(resp.Body)
+
+	if verifyErr != nil {
+		t.Fatalf("verifyNodeHMAC rejected a legitimately signed request: %v", verifyErr)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+}
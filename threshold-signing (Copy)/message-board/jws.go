@@ -0,0 +1,215 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// jwsAlgorithm is the only signing algorithm this board accepts for message
+// payloads: EdDSA over ed25519, matching the key type nodes already use for
+// their identity keys (see KeyStore).
+const jwsAlgorithm = "EdDSA"
+
+// JSONWebKey is the minimal RFC 7517 JWK shape this board accepts: an
+// Octet Key Pair (OKP) carrying an ed25519 public key, the only key type
+// jwsAlgorithm supports.
+type JSONWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+// publicKey decodes jwk's "x" field into an ed25519.PublicKey, validating
+// that it is an OKP/Ed25519 key of the right size.
+func (jwk JSONWebKey) publicKey() (ed25519.PublicKey, error) {
+	if jwk.Kty != "OKP" || jwk.Crv != "Ed25519" {
+		return nil, fmt.Errorf("unsupported JWK kty/crv: %s/%s (only OKP/Ed25519 is accepted)", jwk.Kty, jwk.Crv)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("decoding JWK x: %w", err)
+	}
+	if len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("JWK x is not an ed25519 public key")
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// JWKStore holds the JWK each node has registered via POST /nodes/{id}/jwk,
+// binding every message payload's JWS to a verification key the way ACME
+// binds every request to an account JWK. It is distinct from KeyStore: a
+// node's ed25519 identity key authenticates the HTTP-level NodeMessage
+// envelope, while its JWK authenticates the payload carried inside it.
+type JWKStore struct {
+	mu   sync.RWMutex
+	keys map[string]ed25519.PublicKey
+}
+
+// NewJWKStore creates an empty JWKStore.
+func NewJWKStore() *JWKStore {
+	return &JWKStore{keys: make(map[string]ed25519.PublicKey)}
+}
+
+// Register validates and stores nodeID's JWK. Re-registering with the same
+// key is idempotent; re-registering with a different key is rejected so a
+// node's verification key can't be silently replaced.
+func (s *JWKStore) Register(nodeID string, jwk JSONWebKey) error {
+	pub, err := jwk.publicKey()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if existing, ok := s.keys[nodeID]; ok && !existing.Equal(pub) {
+		return fmt.Errorf("node %s already has a different JWK registered", nodeID)
+	}
+	s.keys[nodeID] = pub
+	return nil
+}
+
+// PublicKey returns the registered JWK public key for nodeID, or nil if the
+// node has not registered one.
+func (s *JWKStore) PublicKey(nodeID string) ed25519.PublicKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.keys[nodeID]
+}
+
+// jwsProtectedHeader is the protected header every message payload's JWS
+// carries, binding it to the specific node, request, round, and recipient
+// it was produced for, so a receiving node can reverify authorship without
+// trusting that the board checked it correctly.
+type jwsProtectedHeader struct {
+	Alg       string `json:"alg"`
+	Kid       string `json:"kid"`
+	RequestID string `json:"mpc_request_id"`
+	Round     *int   `json:"mpc_round,omitempty"`
+	ToNode    string `json:"mpc_to_node,omitempty"`
+}
+
+// SignJWS produces a compact JWS (RFC 7515 section 7.1) over payload, signed with
+// priv under header. It is exported as a plain function, rather than only
+// living inside a client wrapper, so tests and the node-side code that
+// calls export-outgoing/a node's HTTP client can construct one directly.
+func SignJWS(priv ed25519.PrivateKey, header jwsProtectedHeader, payload []byte) (string, error) {
+	header.Alg = jwsAlgorithm
+	headerJSON, err := json.Marshal(header)
+	if err != nil {
+		return "", fmt.Errorf("encoding JWS header: %w", err)
+	}
+
+	encodedHeader := base64.RawURLEncoding.EncodeToString(headerJSON)
+	encodedPayload := base64.RawURLEncoding.EncodeToString(payload)
+	signingInput := encodedHeader + "." + encodedPayload
+
+	sig := ed25519.Sign(priv, []byte(signingInput))
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig), nil
+}
+
+// parsedJWS is a compact JWS split into its three segments, decoded but not
+// yet signature-verified.
+type parsedJWS struct {
+	header       jwsProtectedHeader
+	payload      []byte
+	signature    []byte
+	signingInput string
+}
+
+// parseJWS splits and decodes compact into a parsedJWS, without verifying
+// its signature.
+func parseJWS(compact string) (*parsedJWS, error) {
+	parts := strings.Split(compact, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("payload is not a compact JWS")
+	}
+
+	headerJSON, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("decoding JWS header: %w", err)
+	}
+	var header jwsProtectedHeader
+	if err := json.Unmarshal(headerJSON, &header); err != nil {
+		return nil, fmt.Errorf("decoding JWS header: %w", err)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("decoding JWS payload: %w", err)
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("decoding JWS signature: %w", err)
+	}
+
+	return &parsedJWS{
+		header:       header,
+		payload:      payload,
+		signature:    sig,
+		signingInput: parts[0] + "." + parts[1],
+	}, nil
+}
+
+// jwsPayload extracts compact's payload segment without verifying its
+// signature. Callers use this to read a payload the board already verified
+// at POST time (see signer.go's combineECDSAPartials and
+// combineBLSPartials), not as a substitute for
+// verifyJWS.
+func jwsPayload(compact string) ([]byte, error) {
+	parsed, err := parseJWS(compact)
+	if err != nil {
+		return nil, err
+	}
+	return parsed.payload, nil
+}
+
+// verifyJWS verifies compact as a message payload from fromNode: its alg is
+// jwsAlgorithm, it verifies against fromNode's JWK registered in jwks, and
+// its header's kid/mpc_request_id/mpc_to_node/mpc_round match the request
+// it was attached to. toNode and round are nil for message kinds that don't
+// carry them (e.g. partial signatures), in which case the corresponding
+// header field must be empty/absent. On success it returns the decoded
+// payload.
+func verifyJWS(jwks *JWKStore, compact, fromNode, requestID string, toNode *string, round *int) ([]byte, error) {
+	parsed, err := parseJWS(compact)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+	if parsed.header.Alg != jwsAlgorithm {
+		return nil, fmt.Errorf("%w: unsupported JWS alg %q", ErrUnauthorized, parsed.header.Alg)
+	}
+	if parsed.header.Kid != fromNode {
+		return nil, fmt.Errorf("%w: JWS kid %q does not match from_node %q", ErrUnauthorized, parsed.header.Kid, fromNode)
+	}
+	if parsed.header.RequestID != requestID {
+		return nil, fmt.Errorf("%w: JWS mpc_request_id does not match request_id", ErrUnauthorized)
+	}
+	if toNode != nil && parsed.header.ToNode != *toNode {
+		return nil, fmt.Errorf("%w: JWS mpc_to_node does not match to_node", ErrUnauthorized)
+	}
+	if round != nil && (parsed.header.Round == nil || *parsed.header.Round != *round) {
+		return nil, fmt.Errorf("%w: JWS mpc_round does not match round", ErrUnauthorized)
+	}
+	if (toNode == nil && parsed.header.ToNode != "") || (round == nil && parsed.header.Round != nil) {
+		return nil, fmt.Errorf("%w: JWS carries a claim this message kind doesn't use", ErrUnauthorized)
+	}
+
+	pub := jwks.PublicKey(fromNode)
+	if pub == nil {
+		return nil, fmt.Errorf("%w: node %s has not registered a JWK", ErrUnauthorized, fromNode)
+	}
+	if !ed25519.Verify(pub, []byte(parsed.signingInput), parsed.signature) {
+		return nil, fmt.Errorf("%w: JWS signature does not verify for node %s", ErrUnauthorized, fromNode)
+	}
+
+	return parsed.payload, nil
+}
+
+// jwsRound is a small helper so call sites can take the address of an int
+// literal (round) inline when calling verifyJWS.
+func jwsRound(round int) *int { return &round }
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// registerJWK builds a JSONWebKey from pub and registers it for nodeID, the
+// shape a node produces when it calls POST /nodes/{id}/jwk.
+func registerJWK(t *testing.T, store *JWKStore, nodeID string, pub ed25519.PublicKey) {
+	t.Helper()
+	jwk := JSONWebKey{Kid: nodeID, Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(pub)}
+	if err := store.Register(nodeID, jwk); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+}
+
+// TestVerifyJWSAccepts confirms the golden path: a payload signed by
+// fromNode's registered JWK, with header claims matching the call's
+// requestID/toNode/round, verifies and returns the payload.
+func TestVerifyJWSAccepts(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	jwks := NewJWKStore()
+	registerJWK(t, jwks, "node-1", pub)
+
+	round := 2
+	compact, err := SignJWS(priv, jwsProtectedHeader{Kid: "node-1", RequestID: "req-1", ToNode: "node-2", Round: &round}, []byte("payload"))
+	if err != nil {
+		t.Fatalf("SignJWS: %v", err)
+	}
+
+	toNode := "node-2"
+	payload, err := verifyJWS(jwks, compact, "node-1", "req-1", &toNode, &round)
+	if err != nil {
+		t.Fatalf("verifyJWS rejected a genuine JWS: %v", err)
+	}
+	if string(payload) != "payload" {
+		t.Fatalf("payload = %q, want %q", payload, "payload")
+	}
+}
+
+// TestVerifyJWSRejectsMismatches covers every binding verifyJWS enforces:
+// a JWS signed for one node/request/recipient/round must not verify
+// against a different one, closing the gap where a replayed or
+// cross-wired payload could be accepted as if it were addressed here.
+func TestVerifyJWSRejectsMismatches(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	jwks := NewJWKStore()
+	registerJWK(t, jwks, "node-1", pub)
+
+	round := 2
+	sign := func(header jwsProtectedHeader) string {
+		compact, err := SignJWS(priv, header, []byte("payload"))
+		if err != nil {
+			t.Fatalf("SignJWS: %v", err)
+		}
+		return compact
+	}
+	toNode := "node-2"
+	genuine := jwsProtectedHeader{Kid: "node-1", RequestID: "req-1", ToNode: toNode, Round: &round}
+
+	t.Run("wrong kid", func(t *testing.T) {
+		compact := sign(genuine)
+		// Claim the payload as coming from a different node than it was
+		// actually signed for.
+		if _, err := verifyJWS(jwks, compact, "node-evil", "req-1", &toNode, &round); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("err = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("wrong request id", func(t *testing.T) {
+		compact := sign(genuine)
+		if _, err := verifyJWS(jwks, compact, "node-1", "req-2", &toNode, &round); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("err = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("wrong to_node", func(t *testing.T) {
+		compact := sign(genuine)
+		otherNode := "node-3"
+		if _, err := verifyJWS(jwks, compact, "node-1", "req-1", &otherNode, &round); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("err = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("wrong round", func(t *testing.T) {
+		compact := sign(genuine)
+		otherRound := 3
+		if _, err := verifyJWS(jwks, compact, "node-1", "req-1", &toNode, &otherRound); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("err = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("unexpected to_node claim", func(t *testing.T) {
+		// A partial-signature JWS (toNode == nil) must not accept a
+		// payload carrying a mpc_to_node claim meant for a message kind.
+		compact := sign(genuine)
+		if _, err := verifyJWS(jwks, compact, "node-1", "req-1", nil, &round); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("err = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("tampered payload", func(t *testing.T) {
+		compact := sign(genuine)
+		// Corrupt the payload segment (the middle of the three dot-separated
+		// segments) without re-signing. Flipping a character adjacent to the
+		// segment boundary, rather than the very last character of the whole
+		// string, avoids landing on unused padding bits a base64url decoder
+		// ignores, which would leave the decoded bytes (and the test)
+		// unchanged.
+		segments := strings.Split(compact, ".")
+		if len(segments) != 3 {
+			t.Fatalf("compact JWS has %d segments, want 3", len(segments))
+		}
+		payload := []byte(segments[1])
+		mid := len(payload) / 2
+		if payload[mid] == 'A' {
+			payload[mid] = 'B'
+		} else {
+			payload[mid] = 'A'
+		}
+		segments[1] = string(payload)
+		tampered := strings.Join(segments, ".")
+		if tampered == compact {
+			t.Fatal("tampering left the JWS unchanged")
+		}
+		if _, err := verifyJWS(jwks, tampered, "node-1", "req-1", &toNode, &round); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("err = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("unregistered node", func(t *testing.T) {
+		compact := sign(genuine)
+		if _, err := verifyJWS(NewJWKStore(), compact, "node-1", "req-1", &toNode, &round); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("err = %v, want ErrUnauthorized", err)
+		}
+	})
+}
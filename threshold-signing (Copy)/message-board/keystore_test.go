@@ -0,0 +1,139 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestVerifyNodeSignatureAcceptsGenuine confirms the golden path: a
+// signature produced over signedParts(parts) by fromNode's own key
+// verifies.
+func TestVerifyNodeSignatureAcceptsGenuine(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	ks := NewKeyStore()
+	if err := ks.Register("node-1", pub); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	parts := []string{"req-1", "node-1", "node-2", "1", "payload"}
+	sig := ed25519.Sign(priv, signedParts(parts))
+
+	if err := verifyNodeSignature(ks, "node-1", hex.EncodeToString(sig), parts...); err != nil {
+		t.Fatalf("verifyNodeSignature rejected a genuine signature: %v", err)
+	}
+}
+
+// TestVerifyNodeSignatureRejectsTampering covers the cases a forged or
+// replayed message must fail on: a part changed after signing, a
+// signature from the wrong node's key, and an unregistered node. Each
+// must fail closed with ErrUnauthorized, not just "not equal to true".
+func TestVerifyNodeSignatureRejectsTampering(t *testing.T) {
+	pubA, privA, _ := ed25519.GenerateKey(nil)
+	pubB, _, _ := ed25519.GenerateKey(nil)
+	ks := NewKeyStore()
+	if err := ks.Register("node-a", pubA); err != nil {
+		t.Fatalf("Register node-a: %v", err)
+	}
+	if err := ks.Register("node-b", pubB); err != nil {
+		t.Fatalf("Register node-b: %v", err)
+	}
+
+	parts := []string{"req-1", "node-a", "node-b", "1", "payload"}
+	sig := ed25519.Sign(privA, signedParts(parts))
+	sigHex := hex.EncodeToString(sig)
+
+	t.Run("tampered payload", func(t *testing.T) {
+		tampered := []string{"req-1", "node-a", "node-b", "1", "payload-tampered"}
+		if err := verifyNodeSignature(ks, "node-a", sigHex, tampered...); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("verifyNodeSignature(tampered) err = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("wrong signer key", func(t *testing.T) {
+		if err := verifyNodeSignature(ks, "node-b", sigHex, parts...); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("verifyNodeSignature(wrong key) err = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("unregistered node", func(t *testing.T) {
+		if err := verifyNodeSignature(ks, "node-ghost", sigHex, parts...); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("verifyNodeSignature(unregistered) err = %v, want ErrUnauthorized", err)
+		}
+	})
+
+	t.Run("malformed signature", func(t *testing.T) {
+		if err := verifyNodeSignature(ks, "node-a", "not-hex", parts...); !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("verifyNodeSignature(malformed sig) err = %v, want ErrUnauthorized", err)
+		}
+	})
+}
+
+// TestSignedPartsNoBoundaryForging guards the fix replacing a plain "|"
+// join: two different part lists whose elements would concatenate to the
+// same bytes under a naive separator join must still encode to different
+// signed messages.
+func TestSignedPartsNoBoundaryForging(t *testing.T) {
+	a := signedParts([]string{"ab", "c"})
+	b := signedParts([]string{"a", "bc"})
+	if string(a) == string(b) {
+		t.Fatalf("signedParts(%q) == signedParts(%q): boundary forging is possible", []string{"ab", "c"}, []string{"a", "bc"})
+	}
+}
+
+// TestVerifyNodeMessageRejectsTampering covers VerifyNodeMessage, the
+// self-contained re-verification path a node uses against a fetched
+// message's embedded SignerPub: a single tampered field must invalidate
+// the signature.
+func TestVerifyNodeMessageRejectsTampering(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	createdAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	msg := &NodeMessage{
+		RequestID: "req-1",
+		FromNode:  "node-a",
+		ToNode:    "node-b",
+		Round:     1,
+		Payload:   "payload",
+		CreatedAt: createdAt,
+		SignerPub: hex.EncodeToString(pub),
+	}
+	parts := []string{msg.RequestID, msg.FromNode, msg.ToNode, "1", msg.Payload, createdAt.Format(time.RFC3339Nano)}
+	msg.Signature = hex.EncodeToString(ed25519.Sign(priv, signedParts(parts)))
+
+	if !VerifyNodeMessage(msg) {
+		t.Fatal("VerifyNodeMessage rejected a genuine message")
+	}
+
+	tampered := *msg
+	tampered.Payload = "payload-tampered"
+	if VerifyNodeMessage(&tampered) {
+		t.Fatal("VerifyNodeMessage accepted a message with a tampered payload")
+	}
+}
+
+// TestKeyStoreRegisterRejectsKeyReplacement covers the anti-hijack check:
+// re-registering a node ID under a different key must fail, while
+// re-registering the same key is idempotent.
+func TestKeyStoreRegisterRejectsKeyReplacement(t *testing.T) {
+	pub1, _, _ := ed25519.GenerateKey(nil)
+	pub2, _, _ := ed25519.GenerateKey(nil)
+	ks := NewKeyStore()
+
+	if err := ks.Register("node-1", pub1); err != nil {
+		t.Fatalf("initial Register: %v", err)
+	}
+	if err := ks.Register("node-1", pub1); err != nil {
+		t.Fatalf("idempotent re-Register: %v", err)
+	}
+	if err := ks.Register("node-1", pub2); err == nil {
+		t.Fatal("Register accepted replacing node-1's key, want error")
+	}
+}
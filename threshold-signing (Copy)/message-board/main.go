@@ -1,19 +1,131 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"message-board/apierr"
+	"message-board/messageboardpb"
 )
 
 // Global store instance shared across all HTTP handlers
 var store *Store
 
+// board adapts store to the messageboardpb.MessageBoard interface
+// generated from openapi/message_board.yaml; handlers below decode into
+// the matching messageboardpb Input type and call through board instead
+// of hand-rolling JSON glue and Store calls per endpoint.
+var board messageboardpb.MessageBoard = boardServer{}
+
+// nodeCredentials and nodeNonces back requireNodeHMAC: every mutating
+// request must carry an Authorization header verifiable against a
+// credential provisioned here (see MPC_BOARD_CREDENTIALS_PATH), and
+// nodeNonces rejects a previously-seen signature as a replay.
+var (
+	nodeCredentials = NewCredentialStore()
+	nodeNonces      = newNonceCache(defaultNonceCacheSize)
+)
+
+// streamTimeout bounds how long an SSE stream handler blocks waiting for a
+// new event before closing the connection, so a client with nothing new to
+// see still gets a timely response instead of hanging behind a proxy
+// forever. Configurable via MPC_BOARD_STREAM_TIMEOUT (seconds).
+var streamTimeout = 30 * time.Second
+
 func main() {
-	// Initialize the store
-	store = NewStore()
+	// Initialize the store, honoring a configured quorum size
+	quorumSize := defaultQuorumSize
+	if raw := os.Getenv("MPC_BOARD_QUORUM_SIZE"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			log.Fatalf("invalid MPC_BOARD_QUORUM_SIZE %q: %v", raw, err)
+		}
+		quorumSize = parsed
+	}
+	if raw := os.Getenv("MPC_BOARD_STREAM_TIMEOUT"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 {
+			log.Fatalf("invalid MPC_BOARD_STREAM_TIMEOUT %q: %v", raw, err)
+		}
+		streamTimeout = time.Duration(parsed) * time.Second
+	}
+	configureRequestDeadline()
+	// Use a durable FileLog when MPC_BOARD_LOG_PATH is set, so the board can
+	// replay its state after a crash mid-DKG; otherwise fall back to an
+	// in-memory log that does not survive a restart.
+	var messageLog MessageLog
+	if logPath := os.Getenv("MPC_BOARD_LOG_PATH"); logPath != "" {
+		fileLog, err := NewFileLog(logPath)
+		if err != nil {
+			log.Fatalf("opening message log: %v", err)
+		}
+		messageLog = fileLog
+	} else {
+		messageLog = NewInMemoryLog()
+	}
+
+	// Enable the tamper-evident audit log (see translog.go) only when both
+	// MPC_BOARD_AUDIT_LOG_PATH and MPC_BOARD_SIGNING_KEY_PATH are set: an
+	// audit log whose STH nobody can verify isn't worth keeping.
+	var auditLog *TransparencyLog
+	if auditPath := os.Getenv("MPC_BOARD_AUDIT_LOG_PATH"); auditPath != "" {
+		keyPath := os.Getenv("MPC_BOARD_SIGNING_KEY_PATH")
+		if keyPath == "" {
+			log.Fatalf("MPC_BOARD_AUDIT_LOG_PATH is set but MPC_BOARD_SIGNING_KEY_PATH is not")
+		}
+		signingKey, err := loadBoardSigningKey(keyPath)
+		if err != nil {
+			log.Fatalf("loading board signing key: %v", err)
+		}
+		auditLog, err = NewTransparencyLog(auditPath, signingKey)
+		if err != nil {
+			log.Fatalf("opening audit log: %v", err)
+		}
+	}
+
+	var err error
+	store, err = NewStoreWithLog(quorumSize, messageLog, auditLog)
+	if err != nil {
+		log.Fatalf("initializing store: %v", err)
+	}
+
+	// Gossip the STH periodically so external auditors don't have to poll
+	// GET /log/sth themselves; disabled unless both the audit log and a
+	// webhook are configured.
+	if auditLog != nil {
+		if webhookURL := os.Getenv("MPC_BOARD_STH_WEBHOOK_URL"); webhookURL != "" {
+			interval := defaultSTHGossipInterval
+			if raw := os.Getenv("MPC_BOARD_STH_GOSSIP_INTERVAL"); raw != "" {
+				parsed, err := strconv.Atoi(raw)
+				if err != nil || parsed < 1 {
+					log.Fatalf("invalid MPC_BOARD_STH_GOSSIP_INTERVAL %q: %v", raw, err)
+				}
+				interval = time.Duration(parsed) * time.Second
+			}
+			go gossipSTH(auditLog, webhookURL, interval)
+		}
+	}
+
+	// Provision node HMAC credentials from a JSON file (an array of
+	// NodeCredential) rather than over HTTP, since the credentials
+	// themselves are what make every other endpoint trustworthy.
+	if credsPath := os.Getenv("MPC_BOARD_CREDENTIALS_PATH"); credsPath != "" {
+		if err := loadNodeCredentials(credsPath); err != nil {
+			log.Fatalf("loading node credentials: %v", err)
+		}
+	}
 
 	// Get port from environment variable, default to 8080
 	port := os.Getenv("PORT")
@@ -21,64 +133,146 @@ func main() {
 		port = "8080"
 	}
 
-	// Register HTTP handlers
-	http.HandleFunc("/health", handleHealth)
-	http.HandleFunc("/publickey", handlePublicKey)
-	http.HandleFunc("/requests", handleRequests)
-	http.HandleFunc("/requests/", handleRequestByID)
-	http.HandleFunc("/messages", handleMessages)
-	http.HandleFunc("/presignature-requests", handlePresignatureRequests)
-	http.HandleFunc("/presignature-requests/", handlePresignatureRequestByID)
-	http.HandleFunc("/presignature-messages", handlePresignatureMessages)
-	http.HandleFunc("/partial-signatures", handlePartialSignatures)
+	// Register HTTP handlers. requireNodeHMAC wraps every endpoint that
+	// accepts a mutating (non-GET) request from a node; it passes GET
+	// requests through unauthenticated, since those don't need a node
+	// identity and predate this scheme. Each is registered as a Handler
+	// (see handler.go) rather than a bare HandleFunc, so it gets a uniform
+	// 405, a per-request deadline, panic recovery, and Prometheus metrics
+	// for free. Method is left empty for endpoints that dispatch more than
+	// one method themselves.
+	http.Handle("/health", Handler{Endpoint: "health", Method: http.MethodGet, Func: handleHealth})
+	http.Handle("/nodes", Handler{Endpoint: "nodes", Method: http.MethodPost, Func: requireNodeHMAC(false, handleRegisterNode)})
+	http.Handle("/nodes/", Handler{Endpoint: "nodes_jwk", Method: http.MethodPost, Func: requireNodeHMAC(false, handleRegisterNodeJWK)})
+	http.Handle("/publickey", Handler{Endpoint: "publickey", Func: requireNodeHMAC(false, handlePublicKey)})
+	http.Handle("/requests", Handler{Endpoint: "requests", Func: requireNodeHMAC(false, handleRequests)})
+	// requests_by_id, presignature_requests_by_id, and
+	// partial_signatures_stream are NoDeadline: each dispatches to (or is
+	// itself) an SSE stream handler designed to hold the connection open
+	// for up to streamTimeout, which is far longer than requestDeadline;
+	// the blanket deadline would otherwise cut every stream short. The
+	// non-streaming branches apply requestDeadline themselves via
+	// withRequestDeadline (GET and PUT of requests_by_id, PUT of
+	// presignature_requests_by_id), since only their stream sub-routes need
+	// the exemption. GET of presignature_requests_by_id reads the store
+	// without a context and runs with no deadline.
+	http.Handle("/requests/", Handler{Endpoint: "requests_by_id", Func: requireNodeHMAC(false, handleRequestByID), NoDeadline: true})
+	http.Handle("/messages", Handler{Endpoint: "messages", Func: requireNodeHMAC(true, handleMessages)})
+	http.Handle("/presignature-requests", Handler{Endpoint: "presignature_requests", Func: requireNodeHMAC(false, handlePresignatureRequests)})
+	http.Handle("/presignature-requests/", Handler{Endpoint: "presignature_requests_by_id", Func: requireNodeHMAC(false, handlePresignatureRequestByID), NoDeadline: true})
+	http.Handle("/presignature-messages", Handler{Endpoint: "presignature_messages", Func: requireNodeHMAC(true, handlePresignatureMessages)})
+	http.Handle("/partial-signatures", Handler{Endpoint: "partial_signatures", Func: requireNodeHMAC(true, handlePartialSignatures)})
+	http.Handle("/partial-signatures/stream", Handler{Endpoint: "partial_signatures_stream", Method: http.MethodGet, Func: handlePartialSignatureStream, NoDeadline: true})
+	http.Handle("/airgap/import", Handler{Endpoint: "airgap_import", Method: http.MethodPost, Func: requireNodeHMAC(false, handleAirgapImport)})
+	http.Handle("/log/sth", Handler{Endpoint: "log_sth", Method: http.MethodGet, Func: handleAuditSTH})
+	http.Handle("/log/proof/inclusion", Handler{Endpoint: "log_proof_inclusion", Method: http.MethodGet, Func: handleAuditInclusionProof})
+	http.Handle("/log/proof/consistency", Handler{Endpoint: "log_proof_consistency", Method: http.MethodGet, Func: handleAuditConsistencyProof})
+	http.Handle("/metrics", promhttp.Handler())
 
 	// Start the HTTP server
 	log.Printf("MessageBoard starting on port %s", port)
 	log.Fatal(http.ListenAndServe(":"+port, nil))
 }
 
+// loadNodeCredentials reads a JSON array of NodeCredential from path and
+// provisions each into nodeCredentials.
+func loadNodeCredentials(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+	var creds []NodeCredential
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return fmt.Errorf("decoding %s: %w", path, err)
+	}
+	for _, cred := range creds {
+		if err := nodeCredentials.Provision(cred); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// authenticatedNodeIDKey is the context.Context key requireNodeHMAC attaches
+// the verified credential's NodeID under, for handlers that need to bind a
+// field other than the generic "from_node" body check (e.g. handleRegisterNode,
+// handleRegisterNodeJWK) to the authenticated identity themselves; see
+// authenticatedNodeID.
+type authenticatedNodeIDKey struct{}
+
+// authenticatedNodeID retrieves the NodeID requireNodeHMAC verified for ctx's
+// request, or ok == false if the request reached the handler unauthenticated
+// (a GET, which requireNodeHMAC passes through without verification).
+func authenticatedNodeID(ctx context.Context) (string, bool) {
+	nodeID, ok := ctx.Value(authenticatedNodeIDKey{}).(string)
+	return nodeID, ok
+}
+
+// requireNodeHMAC wraps next so that a non-GET request only reaches it once
+// its Authorization header verifies against nodeCredentials (see
+// verifyNodeHMAC); GET requests pass through unauthenticated. checkFromNode
+// should be true for endpoints whose JSON body has a "from_node" field
+// (messages, presignature-messages, partial-signatures), so a valid
+// credential can't be used to post on another node's behalf. Every
+// authenticated request's verified NodeID is attached to its context (see
+// authenticatedNodeID) for handlers that need to bind a different field.
+func requireNodeHMAC(checkFromNode bool, next HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		if r.Method == http.MethodGet {
+			return next(w, r)
+		}
+		nodeID, err := verifyNodeHMAC(nodeCredentials, nodeNonces, r, checkFromNode)
+		if err != nil {
+			log.Printf("Rejected unauthenticated request to %s: %v", r.URL.Path, err)
+			return apierr.Unauthorized("unauthorized", err.Error(), err)
+		}
+		return next(w, r.WithContext(context.WithValue(r.Context(), authenticatedNodeIDKey{}, nodeID)))
+	}
+}
+
+// methodNotAllowed is the error a multi-method dispatcher returns for any
+// method it doesn't handle itself.
+func methodNotAllowed() error {
+	return apierr.New(http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed", nil)
+}
+
 // handleHealth responds to health check requests
 // Used by container orchestration to verify the service is running
-func handleHealth(w http.ResponseWriter, r *http.Request) {
+func handleHealth(w http.ResponseWriter, r *http.Request) error {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
+	return nil
 }
 
 // handlePresignatureRequests manages presignature request creation and listing
 // POST /presignature-requests - creates a new presignature generation request
 // GET /presignature-requests?status=pending - lists presignature requests
-func handlePresignatureRequests(w http.ResponseWriter, r *http.Request) {
+func handlePresignatureRequests(w http.ResponseWriter, r *http.Request) error {
 	switch r.Method {
 	case http.MethodPost:
-		handleCreatePresignatureRequest(w, r)
+		return handleCreatePresignatureRequest(w, r)
 	case http.MethodGet:
-		handleListPresignatureRequests(w, r)
+		return handleListPresignatureRequests(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return methodNotAllowed()
 	}
 }
 
 // handleCreatePresignatureRequest creates a new presignature generation request
-func handleCreatePresignatureRequest(w http.ResponseWriter, r *http.Request) {
-	// Create the presignature request
-	req, err := store.CreatePresignatureRequest()
+func handleCreatePresignatureRequest(w http.ResponseWriter, r *http.Request) error {
+	out, err := board.CreatePresignatureRequest(r.Context())
 	if err != nil {
-		log.Printf("Error creating presignature request: %v", err)
-		http.Error(w, "Failed to create presignature request", http.StatusInternalServerError)
-		return
+		return apierr.Internal("presignature_request_create_failed", "failed to create presignature request", err)
 	}
 
-	// Return the created request
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{
-		"request_id": req.ID,
-		"status":     req.Status,
-	})
+	json.NewEncoder(w).Encode(out)
+	return nil
 }
 
 // handleListPresignatureRequests lists all presignature requests, optionally filtered by status
-func handleListPresignatureRequests(w http.ResponseWriter, r *http.Request) {
+func handleListPresignatureRequests(w http.ResponseWriter, r *http.Request) error {
 	statusFilter := r.URL.Query().Get("status")
 	requests := store.ListPresignatureRequests(statusFilter)
 
@@ -87,434 +281,764 @@ func handleListPresignatureRequests(w http.ResponseWriter, r *http.Request) {
 		"requests": requests,
 		"count":    len(requests),
 	})
+	return nil
 }
 
 // handlePresignatureRequestByID manages individual presignature requests
 // GET /presignature-requests/{id} - retrieves presignature request status
 // PUT /presignature-requests/{id} - updates presignature request status
-func handlePresignatureRequestByID(w http.ResponseWriter, r *http.Request) {
+// GET /presignature-requests/{id}/messages/stream - SSE stream of new
+// presignature NodeMessages, see handlePresignatureMessageStream
+func handlePresignatureRequestByID(w http.ResponseWriter, r *http.Request) error {
 	path := strings.TrimPrefix(r.URL.Path, "/presignature-requests/")
-	requestID := strings.Split(path, "/")[0]
+	parts := strings.Split(path, "/")
+	requestID := parts[0]
 
 	if requestID == "" {
-		http.Error(w, "Request ID is required", http.StatusBadRequest)
-		return
+		return apierr.BadRequest("request_id_required", "request ID is required", nil)
 	}
 
+	if len(parts) > 2 && parts[1] == "messages" && parts[2] == "stream" {
+		if r.Method != http.MethodGet {
+			return methodNotAllowed()
+		}
+		handlePresignatureMessageStream(w, r, requestID)
+		return nil
+	}
+
+	// presignature_requests_by_id is registered NoDeadline only because the
+	// messages/stream route above needs to hold the connection past
+	// requestDeadline; the plain PUT branch still gets the bound
+	// Handler.ServeHTTP would have applied for any other endpoint. The GET
+	// branch reads the store without a context and has no deadline.
 	switch r.Method {
 	case http.MethodGet:
-		handleGetPresignatureRequest(w, requestID)
+		return handleGetPresignatureRequest(w, requestID)
 	case http.MethodPut:
-		handleUpdatePresignatureRequest(w, r, requestID)
+		r, cancel := withRequestDeadline(r)
+		defer cancel()
+		return handleUpdatePresignatureRequest(w, r, requestID)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return methodNotAllowed()
 	}
 }
 
 // handleGetPresignatureRequest retrieves a presignature request by ID
-func handleGetPresignatureRequest(w http.ResponseWriter, requestID string) {
+func handleGetPresignatureRequest(w http.ResponseWriter, requestID string) error {
 	req := store.GetPresignatureRequest(requestID)
 	if req == nil {
-		http.Error(w, "Presignature request not found", http.StatusNotFound)
-		return
+		return apierr.NotFound("presignature_request_not_found", "presignature request not found", nil)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(req)
+	return nil
 }
 
 // handleUpdatePresignatureRequest updates a presignature request status
-func handleUpdatePresignatureRequest(w http.ResponseWriter, r *http.Request, requestID string) {
+func handleUpdatePresignatureRequest(w http.ResponseWriter, r *http.Request, requestID string) error {
 	var body map[string]string
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
+		return apierr.BadRequest("invalid_body", "invalid request body", err)
 	}
 
 	status, ok := body["status"]
 	if !ok || status == "" {
-		http.Error(w, "Status is required", http.StatusBadRequest)
-		return
+		return apierr.BadRequest("status_required", "status is required", nil)
 	}
 
-	if err := store.UpdatePresignatureRequestStatus(requestID, status); err != nil {
-		log.Printf("Error updating presignature status: %v", err)
-		http.Error(w, "Failed to update presignature request", http.StatusInternalServerError)
-		return
+	if err := store.UpdatePresignatureRequestStatus(r.Context(), requestID, status); err != nil {
+		return apierr.Internal("presignature_request_update_failed", "failed to update presignature request", err)
 	}
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
+	return nil
 }
 
 // handlePresignatureMessages manages messages for presignature generation
 // POST /presignature-messages - node posts a presignature message
 // GET /presignature-messages?request_id=X&to_node=Y - node retrieves presignature messages
-func handlePresignatureMessages(w http.ResponseWriter, r *http.Request) {
+func handlePresignatureMessages(w http.ResponseWriter, r *http.Request) error {
 	switch r.Method {
 	case http.MethodPost:
-		handlePostPresignatureMessage(w, r)
+		return handlePostPresignatureMessage(w, r)
 	case http.MethodGet:
-		handleGetPresignatureMessages(w, r)
+		return handleGetPresignatureMessages(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return methodNotAllowed()
 	}
 }
 
 // handlePostPresignatureMessage allows a node to post a presignature message
-func handlePostPresignatureMessage(w http.ResponseWriter, r *http.Request) {
-	var body struct {
-		RequestID string `json:"request_id"`
-		FromNode  string `json:"from_node"`
-		ToNode    string `json:"to_node"`
-		Round     int    `json:"round"`
-		Payload   string `json:"payload"`
-	}
+func handlePostPresignatureMessage(w http.ResponseWriter, r *http.Request) error {
+	return postMessage(w, r, "presignature message", "presignature_message_rejected", board.PostPresignatureMessage)
+}
 
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
+// handleGetPresignatureMessages retrieves presignature messages for a node
+// Query parameters: request_id (required), to_node (optional filter),
+// after_offset (optional, default 0) - only messages at or past this offset
+// are returned; the response's next_offset is the value to pass next time.
+func handleGetPresignatureMessages(w http.ResponseWriter, r *http.Request) error {
+	requestID := r.URL.Query().Get("request_id")
+	if requestID == "" {
+		return apierr.BadRequest("request_id_required", "request_id parameter is required", nil)
 	}
 
-	if body.RequestID == "" || body.FromNode == "" || body.ToNode == "" || body.Payload == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
-		return
+	toNode := r.URL.Query().Get("to_node")
+	afterOffset, err := parseAfterOffset(r)
+	if err != nil {
+		return apierr.BadRequest("invalid_after_offset", err.Error(), err)
 	}
 
-	msg, err := store.PostPresignatureMessage(body.RequestID, body.FromNode, body.ToNode, body.Round, body.Payload)
+	out, err := board.GetPresignatureMessages(r.Context(), requestID, toNode, afterOffset)
 	if err != nil {
-		log.Printf("Error posting presignature message: %v", err)
-		http.Error(w, "Failed to post presignature message", http.StatusInternalServerError)
-		return
+		return apierr.Internal("presignature_messages_fetch_failed", err.Error(), err)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message_id": msg.ID,
-	})
+	json.NewEncoder(w).Encode(out)
+	return nil
 }
 
-// handleGetPresignatureMessages retrieves presignature messages for a node
-func handleGetPresignatureMessages(w http.ResponseWriter, r *http.Request) {
-	requestID := r.URL.Query().Get("request_id")
-	if requestID == "" {
-		http.Error(w, "request_id parameter is required", http.StatusBadRequest)
-		return
+// parseAfterOffset parses the optional after_offset query parameter, used
+// by handleGetMessages and handleGetPresignatureMessages to let a polling
+// node fetch only messages it hasn't seen yet instead of rescanning the
+// whole history on every poll. Defaults to 0 when absent.
+func parseAfterOffset(r *http.Request) (int64, error) {
+	raw := r.URL.Query().Get("after_offset")
+	if raw == "" {
+		return 0, nil
 	}
+	offset, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("after_offset must be an integer")
+	}
+	return offset, nil
+}
 
-	toNode := r.URL.Query().Get("to_node")
-	messages := store.GetPresignatureMessagesForRequest(requestID, toNode)
+// handleRegisterNode registers a node's ed25519 identity public key
+// POST /nodes - body: {"node_id": "...", "public_key": "<hex-encoded>"}
+// A node calls this on startup after loading or generating its keypair;
+// every message it posts afterward must be signed with the matching private key.
+// The caller's HMAC-authenticated NodeID must match node_id, so a
+// differently-credentialed node can't squat another node's identity before
+// it has a chance to register itself.
+func handleRegisterNode(w http.ResponseWriter, r *http.Request) error {
+	var body struct {
+		NodeID    string `json:"node_id"`
+		PublicKey string `json:"public_key"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return apierr.BadRequest("invalid_body", "invalid request body", err)
+	}
+	if body.NodeID == "" || body.PublicKey == "" {
+		return apierr.BadRequest("missing_fields", "node_id and public_key are required", nil)
+	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"messages": messages,
-	})
+	if authNodeID, ok := authenticatedNodeID(r.Context()); ok && authNodeID != body.NodeID {
+		return apierr.Unauthorized("unauthorized", "node_id does not match the credential's node", nil)
+	}
+
+	if pubBytes, err := hex.DecodeString(body.PublicKey); err != nil || len(pubBytes) != ed25519.PublicKeySize {
+		return apierr.BadRequest("invalid_public_key", "public_key must be a hex-encoded ed25519 public key", err)
+	}
+
+	if err := board.RegisterNode(r.Context(), &messageboardpb.RegisterNodeInput{NodeID: body.NodeID, PublicKey: body.PublicKey}); err != nil {
+		log.Printf("Error registering node %s: %v", body.NodeID, err)
+		return apierr.Conflict("node_register_conflict", err.Error(), err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+	return nil
+}
+
+// handleRegisterNodeJWK registers the JWK a node signs its message payloads
+// with (see jws.go). POST /nodes/{id}/jwk - body: a JSONWebKey. A node calls
+// this, analogous to an ACME account-key registration, before posting any
+// message whose payload the board should accept as a verifiable JWS. The
+// caller's HMAC-authenticated NodeID must match {id}, so a
+// differently-credentialed node can't claim another node's JWK.
+func handleRegisterNodeJWK(w http.ResponseWriter, r *http.Request) error {
+	nodeID, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/nodes/"), "/jwk")
+	if !ok || nodeID == "" {
+		return apierr.NotFound("not_found", "path must be /nodes/{id}/jwk", nil)
+	}
+
+	if authNodeID, ok := authenticatedNodeID(r.Context()); ok && authNodeID != nodeID {
+		return apierr.Unauthorized("unauthorized", "node id in path does not match the credential's node", nil)
+	}
+
+	var jwk JSONWebKey
+	if err := json.NewDecoder(r.Body).Decode(&jwk); err != nil {
+		return apierr.BadRequest("invalid_body", "invalid request body", err)
+	}
+	jwk.Kid = nodeID
+
+	if err := store.RegisterNodeJWK(r.Context(), nodeID, jwk); err != nil {
+		log.Printf("Error registering JWK for node %s: %v", nodeID, err)
+		return apierr.Conflict("node_jwk_register_conflict", err.Error(), err)
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("OK"))
+	return nil
 }
 
 // handlePublicKey manages the shared public key
-// GET /publickey - retrieve the shared public key for signature verification
+// GET /publickey?scheme=bls12381 - retrieve the shared public key for signature verification
 // POST /publickey - register the public key (called by nodes after keygen)
-func handlePublicKey(w http.ResponseWriter, r *http.Request) {
+// scheme defaults to "ecdsa_secp256k1" when omitted, on both GET and POST
+func handlePublicKey(w http.ResponseWriter, r *http.Request) error {
 	switch r.Method {
 	case http.MethodGet:
-		publicKey := store.GetPublicKey()
-		if publicKey == "" {
-			http.Error(w, "Public key not available yet", http.StatusNotFound)
-			return
+		scheme := r.URL.Query().Get("scheme")
+		out, err := board.GetPublicKey(r.Context(), scheme)
+		if err != nil {
+			return apierr.Internal("public_key_fetch_failed", err.Error(), err)
 		}
-
-		response := map[string]string{
-			"public_key": publicKey,
+		if out == nil {
+			return apierr.NotFound("public_key_not_found", "public key not available yet", nil)
 		}
 
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		json.NewEncoder(w).Encode(out)
+		return nil
 
 	case http.MethodPost:
 		var req struct {
 			PublicKey string `json:"public_key"`
+			Scheme    string `json:"scheme"`
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
-			return
+			return apierr.BadRequest("invalid_body", "invalid request body", err)
 		}
 
 		if req.PublicKey == "" {
-			http.Error(w, "Public key is required", http.StatusBadRequest)
-			return
+			return apierr.BadRequest("public_key_required", "public key is required", nil)
 		}
 
 		// Only set if not already set, or if same value (idempotent)
-		existingKey := store.GetPublicKey()
+		existingKey := store.GetPublicKey(req.Scheme)
 		if existingKey != "" && existingKey != req.PublicKey {
-			http.Error(w, "Public key already set with different value", http.StatusConflict)
-			return
+			return apierr.Conflict("public_key_conflict", "public key already set with different value", nil)
 		}
 
-		store.SetPublicKey(req.PublicKey)
+		if err := board.SetPublicKey(r.Context(), &messageboardpb.SetPublicKeyInput{PublicKey: req.PublicKey, Scheme: req.Scheme}); err != nil {
+			log.Printf("Error setting public key: %v", err)
+			return apierr.Internal("public_key_set_failed", "failed to set public key", err)
+		}
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
+		return nil
 
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return methodNotAllowed()
 	}
 }
 
 // handleMessages manages messages between nodes
 // POST /messages - node posts a message to the board
 // GET /messages?request_id=X&to_node=Y - node retrieves messages
-func handleMessages(w http.ResponseWriter, r *http.Request) {
+func handleMessages(w http.ResponseWriter, r *http.Request) error {
 	switch r.Method {
 	case http.MethodPost:
-		handlePostMessage(w, r)
+		return handlePostMessage(w, r)
 	case http.MethodGet:
-		handleGetMessages(w, r)
+		return handleGetMessages(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return methodNotAllowed()
 	}
 }
 
 // handlePostMessage allows a node to post a message to the board
-func handlePostMessage(w http.ResponseWriter, r *http.Request) {
+func handlePostMessage(w http.ResponseWriter, r *http.Request) error {
+	return postMessage(w, r, "message", "message_rejected", board.PostMessage)
+}
+
+// decodePostMessageInput decodes and validates the JSON body shared by
+// handlePostMessage and handlePostPresignatureMessage: both endpoints post
+// a messageboardpb.PostMessageInput, the only difference being which
+// board method (PostMessage vs. PostPresignatureMessage) the caller passes
+// it to.
+func decodePostMessageInput(r *http.Request) (*messageboardpb.PostMessageInput, error) {
 	var body struct {
 		RequestID string `json:"request_id"`
 		FromNode  string `json:"from_node"`
 		ToNode    string `json:"to_node"`
 		Round     int    `json:"round"`
 		Payload   string `json:"payload"`
+		CreatedAt string `json:"created_at"`
+		Signature string `json:"signature"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
+		return nil, apierr.BadRequest("invalid_body", "invalid request body", err)
 	}
 
-	// Validate required fields
-	if body.RequestID == "" || body.FromNode == "" || body.ToNode == "" || body.Payload == "" {
-		http.Error(w, "Missing required fields", http.StatusBadRequest)
-		return
+	if body.RequestID == "" || body.FromNode == "" || body.ToNode == "" || body.Payload == "" || body.CreatedAt == "" || body.Signature == "" {
+		return nil, apierr.BadRequest("missing_fields", "missing required fields", nil)
 	}
 
-	// Store the message
-	msg, err := store.PostMessage(body.RequestID, body.FromNode, body.ToNode, body.Round, body.Payload)
+	createdAt, err := time.Parse(time.RFC3339Nano, body.CreatedAt)
 	if err != nil {
-		log.Printf("Error posting message: %v", err)
-		http.Error(w, "Failed to post message", http.StatusInternalServerError)
-		return
+		return nil, apierr.BadRequest("invalid_created_at", "created_at must be RFC3339Nano", err)
+	}
+
+	return &messageboardpb.PostMessageInput{
+		RequestID: body.RequestID,
+		FromNode:  body.FromNode,
+		ToNode:    body.ToNode,
+		Round:     body.Round,
+		Payload:   body.Payload,
+		CreatedAt: createdAt,
+		Signature: body.Signature,
+	}, nil
+}
+
+// postMessage is the decode/call/respond path handlePostMessage and
+// handlePostPresignatureMessage share: the two differ only in which board
+// method accepts the posted message and the log/error-code strings used
+// to describe it. kind labels the log line (e.g. "message", "presignature
+// message"); rejectedCode is the apierr code for a non-auth rejection
+// (e.g. a round mismatch against the request's FSM state).
+func postMessage(w http.ResponseWriter, r *http.Request, kind, rejectedCode string, post func(context.Context, *messageboardpb.PostMessageInput) (*messageboardpb.PostMessageOutput, error)) error {
+	in, err := decodePostMessageInput(r)
+	if err != nil {
+		return err
+	}
+
+	out, err := post(r.Context(), in)
+	if err != nil {
+		// An auth failure is a 401 rather than a conflict.
+		log.Printf("Rejected %s for %s: %v", kind, in.RequestID, err)
+		if errors.Is(err, ErrUnauthorized) {
+			return apierr.Unauthorized("unauthorized", err.Error(), err)
+		}
+		return apierr.Conflict(rejectedCode, err.Error(), err)
 	}
 
-	// Return the created message
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{
-		"message_id": msg.ID,
+	json.NewEncoder(w).Encode(out)
+	return nil
+}
+
+// handleAirgapImport accepts NodeMessages a hot node's import-incoming
+// command already reassembled from a cold signer's QR frames (see
+// airgapframe.Reassembler) and posts each through the same authenticated path as
+// handlePostMessage, so an airgapped node is indistinguishable from an
+// online one once its messages reach the board. A node's hot relay and its
+// cold signer share one identity (same NodeID, just an airgapped private
+// key), so every imported message's FromNode must match the caller's
+// HMAC-authenticated NodeID, exactly like handleMessages' checkFromNode
+// check — otherwise a differently-credentialed node could import messages
+// claiming to be from a node it doesn't control.
+func handleAirgapImport(w http.ResponseWriter, r *http.Request) error {
+	var body struct {
+		Messages []NodeMessage `json:"messages"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		return apierr.BadRequest("invalid_body", "invalid request body", err)
+	}
+	if len(body.Messages) == 0 {
+		return apierr.BadRequest("messages_required", "missing required field: messages", nil)
+	}
+
+	authNodeID, haveAuthNodeID := authenticatedNodeID(r.Context())
+
+	imported := make([]string, 0, len(body.Messages))
+	for _, m := range body.Messages {
+		if m.RequestID == "" || m.FromNode == "" || m.ToNode == "" || m.Payload == "" || m.Signature == "" {
+			return apierr.BadRequest("missing_fields", "missing required fields in reassembled message", nil)
+		}
+		if haveAuthNodeID && m.FromNode != authNodeID {
+			return apierr.Unauthorized("unauthorized", "from_node does not match the credential's node", nil)
+		}
+		out, err := board.PostMessage(r.Context(), &messageboardpb.PostMessageInput{
+			RequestID: m.RequestID,
+			FromNode:  m.FromNode,
+			ToNode:    m.ToNode,
+			Round:     m.Round,
+			Payload:   m.Payload,
+			CreatedAt: m.CreatedAt,
+			Signature: m.Signature,
+		})
+		if err != nil {
+			log.Printf("Rejected airgap-imported message for %s: %v", m.RequestID, err)
+			if errors.Is(err, ErrUnauthorized) {
+				return apierr.Unauthorized("unauthorized", err.Error(), err)
+			}
+			return apierr.Conflict("message_rejected", err.Error(), err)
+		}
+		imported = append(imported, out.MessageID)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string][]string{
+		"message_ids": imported,
 	})
+	return nil
 }
 
 // handleGetMessages retrieves messages for a node
-// Query parameters: request_id (required), to_node (optional filter)
-func handleGetMessages(w http.ResponseWriter, r *http.Request) {
+// Query parameters: request_id (required), to_node (optional filter),
+// after_offset (optional, default 0) - only messages at or past this offset
+// are returned; the response's next_offset is the value to pass next time.
+func handleGetMessages(w http.ResponseWriter, r *http.Request) error {
 	// Parse query parameters
 	requestID := r.URL.Query().Get("request_id")
 	if requestID == "" {
-		http.Error(w, "request_id parameter is required", http.StatusBadRequest)
-		return
+		return apierr.BadRequest("request_id_required", "request_id parameter is required", nil)
 	}
 
 	toNode := r.URL.Query().Get("to_node")
+	afterOffset, err := parseAfterOffset(r)
+	if err != nil {
+		return apierr.BadRequest("invalid_after_offset", err.Error(), err)
+	}
 
 	// Retrieve messages from store
-	messages := store.GetMessagesForRequest(requestID, toNode)
+	out, err := board.GetMessages(r.Context(), requestID, toNode, afterOffset)
+	if err != nil {
+		return apierr.Internal("messages_fetch_failed", err.Error(), err)
+	}
 
 	// Return the messages
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"messages": messages,
-	})
+	json.NewEncoder(w).Encode(out)
+	return nil
 }
 
 // handleRequests manages signing request creation and listing
 // POST /requests - creates a new signing request
 // GET /requests?status=pending - lists requests (optionally filtered by status)
-func handleRequests(w http.ResponseWriter, r *http.Request) {
+func handleRequests(w http.ResponseWriter, r *http.Request) error {
 	switch r.Method {
 	case http.MethodPost:
-		handleCreateRequest(w, r)
+		return handleCreateRequest(w, r)
 	case http.MethodGet:
-		handleListRequests(w, r)
+		return handleListRequests(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return methodNotAllowed()
 	}
 }
 
 // handleCreateRequest creates a new signing request
-func handleCreateRequest(w http.ResponseWriter, r *http.Request) {
-
+func handleCreateRequest(w http.ResponseWriter, r *http.Request) error {
 	// Parse the incoming request from API Gateway
 	var body map[string]string
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
+		return apierr.BadRequest("invalid_body", "invalid request body", err)
 	}
 
 	message, ok := body["message"]
 	if !ok || message == "" {
-		http.Error(w, "Message is required", http.StatusBadRequest)
-		return
+		return apierr.BadRequest("message_required", "message is required", nil)
 	}
 
 	// Create the signing request
-	req, err := store.CreateRequest(message)
+	out, err := board.CreateRequest(r.Context(), &messageboardpb.CreateRequestInput{
+		Message:     message,
+		Scheme:      body["scheme"],
+		RequestedBy: body["requested_by"],
+	})
 	if err != nil {
 		log.Printf("Error creating request: %v", err)
-		http.Error(w, "Failed to create request", http.StatusInternalServerError)
-		return
+		return apierr.Internal("request_create_failed", "failed to create request", err)
 	}
 
 	// Return the created request
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]string{
-		"request_id": req.ID,
-		"status":     req.Status,
-	})
+	json.NewEncoder(w).Encode(out)
+	return nil
 }
 
 // handleListRequests lists all signing requests, optionally filtered by status
 // GET /requests - lists all requests
 // GET /requests?status=pending - lists only pending requests
-func handleListRequests(w http.ResponseWriter, r *http.Request) {
+func handleListRequests(w http.ResponseWriter, r *http.Request) error {
 	// Get optional status filter from query parameter
 	statusFilter := r.URL.Query().Get("status")
 
 	// Get all requests from store
-	requests := store.ListRequests(statusFilter)
+	out, err := board.ListRequests(r.Context(), statusFilter)
+	if err != nil {
+		return apierr.Internal("requests_list_failed", err.Error(), err)
+	}
 
 	// Return the list
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"requests": requests,
-		"count":    len(requests),
-	})
+	json.NewEncoder(w).Encode(out)
+	return nil
 }
 
 // handleRequestByID manages individual signing requests
 // GET /requests/{id} - retrieves request status and signature
 // PUT /requests/{id} - updates request (used by nodes to set signature)
-func handleRequestByID(w http.ResponseWriter, r *http.Request) {
+// GET /requests/{id}/state - retrieves the request's current FSM state and expected round
+// GET /requests/{id}/messages/stream - SSE stream of new NodeMessages, see handleMessageStream
+// GET /requests/{id}/status/stream - SSE stream of status transitions, see handleStatusStream
+func handleRequestByID(w http.ResponseWriter, r *http.Request) error {
 	// Extract request ID from URL path
-	// Path is "/requests/{id}", we want the {id} part
+	// Path is "/requests/{id}", "/requests/{id}/state",
+	// "/requests/{id}/messages/stream", or "/requests/{id}/status/stream"
 	path := strings.TrimPrefix(r.URL.Path, "/requests/")
-	requestID := strings.Split(path, "/")[0]
+	parts := strings.Split(path, "/")
+	requestID := parts[0]
 
 	if requestID == "" {
-		http.Error(w, "Request ID is required", http.StatusBadRequest)
-		return
+		return apierr.BadRequest("request_id_required", "request ID is required", nil)
+	}
+
+	if len(parts) > 1 && parts[1] == "state" {
+		if r.Method != http.MethodGet {
+			return methodNotAllowed()
+		}
+		return handleGetRequestState(w, requestID)
+	}
+
+	if len(parts) > 2 && parts[1] == "messages" && parts[2] == "stream" {
+		if r.Method != http.MethodGet {
+			return methodNotAllowed()
+		}
+		handleMessageStream(w, r, requestID)
+		return nil
 	}
 
+	if len(parts) > 2 && parts[1] == "status" && parts[2] == "stream" {
+		if r.Method != http.MethodGet {
+			return methodNotAllowed()
+		}
+		handleStatusStream(w, r, requestID)
+		return nil
+	}
+
+	// requests_by_id is registered NoDeadline only because the stream
+	// routes above need to hold the connection past requestDeadline; these
+	// plain GET/PUT branches still get the bound Handler.ServeHTTP would
+	// have applied for any other endpoint.
 	switch r.Method {
 	case http.MethodGet:
-		handleGetRequest(w, requestID)
+		r, cancel := withRequestDeadline(r)
+		defer cancel()
+		return handleGetRequest(w, r, requestID)
 	case http.MethodPut:
-		handleUpdateRequest(w, r, requestID)
+		r, cancel := withRequestDeadline(r)
+		defer cancel()
+		return handleUpdateRequest(w, r, requestID)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return methodNotAllowed()
 	}
 }
 
+// handleGetRequestState retrieves the current FSM state for a signing request
+func handleGetRequestState(w http.ResponseWriter, requestID string) error {
+	state := store.GetRequestState(requestID)
+	if state == nil {
+		return apierr.NotFound("request_not_found", "request not found", nil)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(state)
+	return nil
+}
+
 // handleGetRequest retrieves a signing request by ID
-func handleGetRequest(w http.ResponseWriter, requestID string) {
-	req := store.GetRequest(requestID)
+func handleGetRequest(w http.ResponseWriter, r *http.Request, requestID string) error {
+	req, err := board.GetRequest(r.Context(), requestID)
+	if err != nil {
+		return apierr.Internal("request_fetch_failed", err.Error(), err)
+	}
 	if req == nil {
-		http.Error(w, "Request not found", http.StatusNotFound)
-		return
+		return apierr.NotFound("request_not_found", "request not found", nil)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(req)
+	return nil
 }
 
 // handleUpdateRequest updates a signing request
 // Nodes use this to set the final signature or update status
-func handleUpdateRequest(w http.ResponseWriter, r *http.Request, requestID string) {
+func handleUpdateRequest(w http.ResponseWriter, r *http.Request, requestID string) error {
 	var body map[string]string
 	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
+		return apierr.BadRequest("invalid_body", "invalid request body", err)
 	}
 
 	// If signature is provided, set it and mark as completed
 	if signature, ok := body["signature"]; ok && signature != "" {
-		if err := store.SetRequestSignature(requestID, signature); err != nil {
+		if err := store.SetRequestSignature(r.Context(), requestID, signature); err != nil {
 			log.Printf("Error setting signature: %v", err)
-			http.Error(w, "Failed to update request", http.StatusInternalServerError)
-			return
+			return apierr.Internal("request_update_failed", "failed to update request", err)
 		}
 	} else if status, ok := body["status"]; ok && status != "" {
 		// Otherwise update just the status
-		if err := store.UpdateRequestStatus(requestID, status); err != nil {
+		if err := store.UpdateRequestStatus(r.Context(), requestID, status); err != nil {
 			log.Printf("Error updating status: %v", err)
-			http.Error(w, "Failed to update request", http.StatusInternalServerError)
-			return
+			return apierr.Internal("request_update_failed", "failed to update request", err)
 		}
 	} else {
-		http.Error(w, "Either signature or status is required", http.StatusBadRequest)
-		return
+		return apierr.BadRequest("signature_or_status_required", "either signature or status is required", nil)
 	}
 
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
+	return nil
 }
 
 // handlePartialSignatures handles partial signature operations for fast signing
-func handlePartialSignatures(w http.ResponseWriter, r *http.Request) {
+func handlePartialSignatures(w http.ResponseWriter, r *http.Request) error {
 	switch r.Method {
 	case http.MethodPost:
-		handlePostPartialSignature(w, r)
+		return handlePostPartialSignature(w, r)
 	case http.MethodGet:
-		handleGetPartialSignatures(w, r)
+		return handleGetPartialSignatures(w, r)
 	default:
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return methodNotAllowed()
 	}
 }
 
 // handlePostPartialSignature posts a partial signature for a request
-func handlePostPartialSignature(w http.ResponseWriter, r *http.Request) {
+func handlePostPartialSignature(w http.ResponseWriter, r *http.Request) error {
 	var req struct {
 		RequestID string `json:"request_id"`
 		FromNode  string `json:"from_node"`
+		Scheme    string `json:"scheme"`
 		Payload   string `json:"payload"`
+		CreatedAt string `json:"created_at"`
+		Signature string `json:"signature"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		return
+		return apierr.BadRequest("invalid_body", "invalid JSON", err)
 	}
 
-	partialSig, err := store.PostPartialSignature(req.RequestID, req.FromNode, req.Payload)
+	createdAt, err := time.Parse(time.RFC3339Nano, req.CreatedAt)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return apierr.BadRequest("invalid_created_at", "created_at must be RFC3339Nano", err)
+	}
+
+	partialSig, err := board.PostPartialSignature(r.Context(), &messageboardpb.PostPartialSignatureInput{
+		RequestID: req.RequestID,
+		FromNode:  req.FromNode,
+		Scheme:    req.Scheme,
+		Payload:   req.Payload,
+		CreatedAt: createdAt,
+		Signature: req.Signature,
+	})
+	if err != nil {
+		if errors.Is(err, ErrUnauthorized) {
+			return apierr.Unauthorized("unauthorized", err.Error(), err)
+		}
+		return apierr.Internal("partial_signature_rejected", err.Error(), err)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(partialSig)
+	return nil
 }
 
 // handleGetPartialSignatures retrieves partial signatures for a request
-func handleGetPartialSignatures(w http.ResponseWriter, r *http.Request) {
+func handleGetPartialSignatures(w http.ResponseWriter, r *http.Request) error {
 	requestID := r.URL.Query().Get("request_id")
 	if requestID == "" {
-		http.Error(w, "request_id parameter required", http.StatusBadRequest)
-		return
+		return apierr.BadRequest("request_id_required", "request_id parameter required", nil)
+	}
+
+	out, err := board.GetPartialSignatures(r.Context(), requestID)
+	if err != nil {
+		return apierr.Internal("partial_signatures_fetch_failed", err.Error(), err)
 	}
 
-	partialSigs := store.GetPartialSignaturesForRequest(requestID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(out)
+	return nil
+}
+
+// handleAuditSTH serves GET /log/sth, the board's current signed
+// commitment to the transparency log (see translog.go). Returns 404 if no
+// audit log was configured.
+func handleAuditSTH(w http.ResponseWriter, r *http.Request) error {
+	sth, ok := store.AuditSTH()
+	if !ok {
+		return apierr.NotFound("audit_log_not_configured", "audit log not configured", nil)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(sth)
+	return nil
+}
+
+// handleAuditInclusionProof serves GET
+// /log/proof/inclusion?leaf_hash=<hex>&tree_size=<n>, returning the audit
+// path proving leaf_hash is included in the first tree_size audit log
+// entries.
+func handleAuditInclusionProof(w http.ResponseWriter, r *http.Request) error {
+	leafHashHex := r.URL.Query().Get("leaf_hash")
+	leafHash, err := hex.DecodeString(leafHashHex)
+	if leafHashHex == "" || err != nil {
+		return apierr.BadRequest("invalid_leaf_hash", "leaf_hash must be a hex-encoded SHA-256 hash", err)
+	}
+
+	treeSize, err := strconv.ParseInt(r.URL.Query().Get("tree_size"), 10, 64)
+	if err != nil {
+		return apierr.BadRequest("invalid_tree_size", "tree_size must be an integer", err)
+	}
+
+	index, path, err := store.AuditInclusionProof(leafHash, treeSize)
+	if err != nil {
+		return apierr.NotFound("inclusion_proof_not_found", err.Error(), err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"leaf_index": index,
+		"tree_size":  treeSize,
+		"audit_path": hexEncodeAll(path),
+	})
+	return nil
+}
+
+// handleAuditConsistencyProof serves GET
+// /log/proof/consistency?first=<n>&second=<n>, returning the proof that
+// every leaf in the tree of size first is still present, in the same
+// order, in the tree of size second.
+func handleAuditConsistencyProof(w http.ResponseWriter, r *http.Request) error {
+	first, err := strconv.ParseInt(r.URL.Query().Get("first"), 10, 64)
+	if err != nil {
+		return apierr.BadRequest("invalid_first", "first must be an integer", err)
+	}
+	second, err := strconv.ParseInt(r.URL.Query().Get("second"), 10, 64)
+	if err != nil {
+		return apierr.BadRequest("invalid_second", "second must be an integer", err)
+	}
+
+	proof, err := store.AuditConsistencyProof(first, second)
+	if err != nil {
+		return apierr.NotFound("consistency_proof_not_found", err.Error(), err)
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"partial_signatures": partialSigs,
+		"first":             first,
+		"second":            second,
+		"consistency_proof": hexEncodeAll(proof),
 	})
+	return nil
+}
+
+// hexEncodeAll hex-encodes each hash in hashes, for JSON responses carrying
+// a Merkle audit path or consistency proof.
+func hexEncodeAll(hashes [][]byte) []string {
+	out := make([]string, len(hashes))
+	for i, h := range hashes {
+		out[i] = hex.EncodeToString(h)
+	}
+	return out
 }
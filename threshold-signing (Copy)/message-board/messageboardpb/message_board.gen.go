@@ -0,0 +1,319 @@
+// Code generated from openapi/message_board.yaml by this repo's
+// openapi-gen step. DO NOT EDIT.
+//
+// Regenerate with: openapi-gen -in openapi/message_board.yaml -out messageboardpb
+//
+// This package is the single source of truth for the MessageBoard wire
+// types: the request/response shapes here, the MessageBoard server
+// interface, and Client all come from the same spec, so a Go node and the
+// board's HTTP handlers in main.go can't drift the way hand-written
+// anonymous structs on both sides eventually do.
+package messageboardpb
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SigningRequest mirrors components.schemas.SigningRequest.
+type SigningRequest struct {
+	ID            string    `json:"id"`
+	Message       string    `json:"message"`
+	Scheme        string    `json:"scheme,omitempty"`
+	RequestedBy   string    `json:"requested_by,omitempty"`
+	Status        string    `json:"status"`
+	Signature     string    `json:"signature,omitempty"`
+	ExpectedRound int       `json:"expected_round"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// NodeMessage mirrors components.schemas.NodeMessage.
+type NodeMessage struct {
+	ID        string    `json:"id"`
+	RequestID string    `json:"request_id"`
+	FromNode  string    `json:"from_node"`
+	ToNode    string    `json:"to_node"`
+	Round     int       `json:"round"`
+	Payload   string    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+	Signature string    `json:"signature"`
+	SignerPub string    `json:"signer_pub"`
+}
+
+// PartialSignatureMessage mirrors components.schemas.PartialSignatureMessage.
+type PartialSignatureMessage struct {
+	ID        string    `json:"id"`
+	RequestID string    `json:"request_id"`
+	FromNode  string    `json:"from_node"`
+	Scheme    string    `json:"scheme,omitempty"`
+	Payload   string    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+	Signature string    `json:"signature"`
+	SignerPub string    `json:"signer_pub"`
+}
+
+type CreateRequestInput struct {
+	Message     string `json:"message"`
+	Scheme      string `json:"scheme,omitempty"`
+	RequestedBy string `json:"requested_by,omitempty"`
+}
+
+type CreateRequestOutput struct {
+	RequestID string `json:"request_id"`
+	Status    string `json:"status"`
+}
+
+type ListRequestsOutput struct {
+	Requests []*SigningRequest `json:"requests"`
+	Count    int               `json:"count"`
+}
+
+type PostMessageInput struct {
+	RequestID string    `json:"request_id"`
+	FromNode  string    `json:"from_node"`
+	ToNode    string    `json:"to_node"`
+	Round     int       `json:"round"`
+	Payload   string    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+	Signature string    `json:"signature"`
+}
+
+type PostMessageOutput struct {
+	MessageID string `json:"message_id"`
+}
+
+type GetMessagesOutput struct {
+	Messages   []*NodeMessage `json:"messages"`
+	NextOffset int64          `json:"next_offset"`
+}
+
+type PostPartialSignatureInput struct {
+	RequestID string    `json:"request_id"`
+	FromNode  string    `json:"from_node"`
+	Scheme    string    `json:"scheme,omitempty"`
+	Payload   string    `json:"payload"`
+	CreatedAt time.Time `json:"created_at"`
+	Signature string    `json:"signature"`
+}
+
+type GetPartialSignaturesOutput struct {
+	PartialSignatures []*PartialSignatureMessage `json:"partial_signatures"`
+}
+
+type RegisterNodeInput struct {
+	NodeID    string `json:"node_id"`
+	PublicKey string `json:"public_key"`
+}
+
+type SetPublicKeyInput struct {
+	PublicKey string `json:"public_key"`
+	Scheme    string `json:"scheme,omitempty"`
+}
+
+type GetPublicKeyOutput struct {
+	PublicKey string `json:"public_key"`
+}
+
+// MessageBoard is the typed server interface generated from the
+// "operationId" of each path in message_board.yaml. main.go's HTTP
+// handlers decode a request into the matching Input type, call the
+// corresponding method on a MessageBoard implementation wired to Store,
+// and encode the Output back to the caller, instead of hand-rolling that
+// glue per endpoint.
+type MessageBoard interface {
+	CreateRequest(ctx context.Context, in *CreateRequestInput) (*CreateRequestOutput, error)
+	GetRequest(ctx context.Context, id string) (*SigningRequest, error)
+	ListRequests(ctx context.Context, statusFilter string) (*ListRequestsOutput, error)
+
+	PostMessage(ctx context.Context, in *PostMessageInput) (*PostMessageOutput, error)
+	GetMessages(ctx context.Context, requestID, toNode string, afterOffset int64) (*GetMessagesOutput, error)
+
+	CreatePresignatureRequest(ctx context.Context) (*CreateRequestOutput, error)
+	PostPresignatureMessage(ctx context.Context, in *PostMessageInput) (*PostMessageOutput, error)
+	GetPresignatureMessages(ctx context.Context, requestID, toNode string, afterOffset int64) (*GetMessagesOutput, error)
+
+	PostPartialSignature(ctx context.Context, in *PostPartialSignatureInput) (*PartialSignatureMessage, error)
+	GetPartialSignatures(ctx context.Context, requestID string) (*GetPartialSignaturesOutput, error)
+
+	RegisterNode(ctx context.Context, in *RegisterNodeInput) error
+	SetPublicKey(ctx context.Context, in *SetPublicKeyInput) error
+	GetPublicKey(ctx context.Context, scheme string) (*GetPublicKeyOutput, error)
+}
+
+// Client is a generated MessageBoard implementation that calls a running
+// board over HTTP, so a node no longer has to hand-maintain its own REST
+// glue in parallel with the board's handlers. BaseURL is the board's root,
+// e.g. "http://message-board:8080". HTTPClient defaults to http.DefaultClient
+// when nil.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+var _ MessageBoard = (*Client)(nil)
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, reqBody, respBody interface{}) error {
+	u := c.BaseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if reqBody != nil {
+		encoded, err := json.Marshal(reqBody)
+		if err != nil {
+			return fmt.Errorf("encoding %s %s body: %w", method, path, err)
+		}
+		body = bytes.NewReader(encoded)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return fmt.Errorf("building %s %s: %w", method, path, err)
+	}
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("%s %s: %s: %s", method, path, resp.Status, bytes.TrimSpace(msg))
+	}
+	if respBody == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(respBody)
+}
+
+func (c *Client) CreateRequest(ctx context.Context, in *CreateRequestInput) (*CreateRequestOutput, error) {
+	var out CreateRequestOutput
+	if err := c.do(ctx, http.MethodPost, "/requests", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetRequest(ctx context.Context, id string) (*SigningRequest, error) {
+	var out SigningRequest
+	if err := c.do(ctx, http.MethodGet, "/requests/"+id, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) ListRequests(ctx context.Context, statusFilter string) (*ListRequestsOutput, error) {
+	q := url.Values{}
+	if statusFilter != "" {
+		q.Set("status", statusFilter)
+	}
+	var out ListRequestsOutput
+	if err := c.do(ctx, http.MethodGet, "/requests", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) PostMessage(ctx context.Context, in *PostMessageInput) (*PostMessageOutput, error) {
+	var out PostMessageOutput
+	if err := c.do(ctx, http.MethodPost, "/messages", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetMessages(ctx context.Context, requestID, toNode string, afterOffset int64) (*GetMessagesOutput, error) {
+	return c.getMessages(ctx, "/messages", requestID, toNode, afterOffset)
+}
+
+func (c *Client) CreatePresignatureRequest(ctx context.Context) (*CreateRequestOutput, error) {
+	var out CreateRequestOutput
+	if err := c.do(ctx, http.MethodPost, "/presignature-requests", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) PostPresignatureMessage(ctx context.Context, in *PostMessageInput) (*PostMessageOutput, error) {
+	var out PostMessageOutput
+	if err := c.do(ctx, http.MethodPost, "/presignature-messages", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetPresignatureMessages(ctx context.Context, requestID, toNode string, afterOffset int64) (*GetMessagesOutput, error) {
+	return c.getMessages(ctx, "/presignature-messages", requestID, toNode, afterOffset)
+}
+
+func (c *Client) getMessages(ctx context.Context, path, requestID, toNode string, afterOffset int64) (*GetMessagesOutput, error) {
+	q := url.Values{"request_id": {requestID}}
+	if toNode != "" {
+		q.Set("to_node", toNode)
+	}
+	if afterOffset != 0 {
+		q.Set("after_offset", strconv.FormatInt(afterOffset, 10))
+	}
+	var out GetMessagesOutput
+	if err := c.do(ctx, http.MethodGet, path, q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) PostPartialSignature(ctx context.Context, in *PostPartialSignatureInput) (*PartialSignatureMessage, error) {
+	var out PartialSignatureMessage
+	if err := c.do(ctx, http.MethodPost, "/partial-signatures", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetPartialSignatures(ctx context.Context, requestID string) (*GetPartialSignaturesOutput, error) {
+	q := url.Values{"request_id": {requestID}}
+	var out GetPartialSignaturesOutput
+	if err := c.do(ctx, http.MethodGet, "/partial-signatures", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) RegisterNode(ctx context.Context, in *RegisterNodeInput) error {
+	return c.do(ctx, http.MethodPost, "/nodes", nil, in, nil)
+}
+
+func (c *Client) SetPublicKey(ctx context.Context, in *SetPublicKeyInput) error {
+	return c.do(ctx, http.MethodPost, "/publickey", nil, in, nil)
+}
+
+func (c *Client) GetPublicKey(ctx context.Context, scheme string) (*GetPublicKeyOutput, error) {
+	q := url.Values{}
+	if scheme != "" {
+		q.Set("scheme", scheme)
+	}
+	var out GetPublicKeyOutput
+	if err := c.do(ctx, http.MethodGet, "/publickey", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
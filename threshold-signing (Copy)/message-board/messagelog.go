@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Log entry kinds. Each corresponds to one of the Store mutations that must
+// survive a MessageBoard restart.
+const (
+	logEntryMessage                    = "message"
+	logEntryPresignatureMessage        = "presignature_message"
+	logEntryPartialSignature           = "partial_signature"
+	logEntryPublicKey                  = "public_key"
+	logEntryRequestCreated             = "request_created"
+	logEntryPresignatureRequestCreated = "presignature_request_created"
+	logEntrySignatureSet               = "signature_set"
+	logEntryStatusUpdate               = "status_update"
+	logEntryPresignatureStatusUpdate   = "presignature_status_update"
+)
+
+// LogEntry is one durable record in a MessageLog. Store appends one of
+// these for every mutation before applying it to its in-memory cache, and
+// replays them in order to rebuild that cache after a restart. Not every
+// field is populated for every Kind; see the apply* methods in store.go for
+// which fields each Kind reads.
+type LogEntry struct {
+	Kind        string    `json:"kind"`
+	RequestID   string    `json:"request_id"`
+	MessageID   string    `json:"message_id,omitempty"`
+	FromNode    string    `json:"from_node,omitempty"`
+	ToNode      string    `json:"to_node,omitempty"`
+	Round       int       `json:"round,omitempty"`
+	Scheme      string    `json:"scheme,omitempty"`
+	Payload     string    `json:"payload,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	RequestedBy string    `json:"requested_by,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Signature   string    `json:"signature,omitempty"`
+	SignerPub   string    `json:"signer_pub,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// MessageLog is the durable, replayable backend for Store mutations. It is
+// keyed conceptually by (RequestID, Round): readers rebuild per-request
+// order by replaying entries in append order and grouping by RequestID.
+// Implementations: InMemoryLog (default, no durability), FileLog
+// (append-only JSON lines with fsync), and KafkaLog (durable, replicated,
+// for multi-process deployments).
+type MessageLog interface {
+	// Append durably records entry. Entries must become visible to Replay
+	// (called on a fresh log instance pointed at the same backing store)
+	// only once Append returns nil.
+	Append(entry LogEntry) error
+	// Replay invokes fn once per entry, in the order they were appended,
+	// so a restarting MessageBoard can rebuild its in-memory cache. fn
+	// must not be called concurrently with Append.
+	Replay(fn func(LogEntry) error) error
+	// Flush blocks until every Append that has returned is durable. For
+	// logs that are durable synchronously (InMemoryLog, FileLog with
+	// fsync-per-write) this is a no-op.
+	Flush() error
+}
+
+// InMemoryLog keeps entries in a process-local slice. It provides replay
+// (useful for tests and for Store's own bookkeeping) but no durability
+// across a process restart; it is the default when no log backend is
+// configured.
+type InMemoryLog struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+// NewInMemoryLog creates an empty InMemoryLog.
+func NewInMemoryLog() *InMemoryLog {
+	return &InMemoryLog{}
+}
+
+func (l *InMemoryLog) Append(entry LogEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = append(l.entries, entry)
+	return nil
+}
+
+func (l *InMemoryLog) Replay(fn func(LogEntry) error) error {
+	l.mu.Lock()
+	entries := make([]LogEntry, len(l.entries))
+	copy(entries, l.entries)
+	l.mu.Unlock()
+
+	for _, e := range entries {
+		if err := fn(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *InMemoryLog) Flush() error { return nil }
+
+// FileLog is an append-only JSON-lines log on disk. Every Append writes one
+// JSON object followed by a newline and fsyncs before returning, so an
+// acknowledged Append survives a MessageBoard crash or host restart.
+type FileLog struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileLog opens (creating if necessary) the JSON-lines log at path for
+// appending.
+func NewFileLog(path string) (*FileLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening message log %s: %w", path, err)
+	}
+	return &FileLog{file: f}, nil
+}
+
+func (l *FileLog) Append(entry LogEntry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding log entry: %w", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := l.file.Write(line); err != nil {
+		return fmt.Errorf("writing log entry: %w", err)
+	}
+	return l.file.Sync()
+}
+
+func (l *FileLog) Replay(fn func(LogEntry) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, err := l.file.Seek(0, 0); err != nil {
+		return fmt.Errorf("seeking message log: %w", err)
+	}
+
+	scanner := bufio.NewScanner(l.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry LogEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return fmt.Errorf("decoding log entry: %w", err)
+		}
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading message log: %w", err)
+	}
+
+	// Resume writing at the end after replay.
+	_, err := l.file.Seek(0, 2)
+	return err
+}
+
+func (l *FileLog) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.file.Sync()
+}
+
+// KafkaProducer is the minimal surface KafkaLog needs from a Kafka client.
+// Deployments inject a concrete implementation (e.g. a thin wrapper around
+// sarama or confluent-kafka-go); KafkaLog itself has no client dependency.
+type KafkaProducer interface {
+	// Produce publishes value to topic, partitioned/ordered by key, and
+	// blocks until the broker acknowledges it (or returns an error).
+	Produce(topic string, key, value []byte) error
+}
+
+// KafkaConsumer is the minimal surface KafkaLog needs to replay a topic.
+type KafkaConsumer interface {
+	// ConsumeFromStart reads every message on topic from the earliest
+	// offset and calls fn for each, in order.
+	ConsumeFromStart(topic string, fn func(key, value []byte) error) error
+}
+
+// kafkaMaxRetries and kafkaBackoffBase bound KafkaLog's retry/backoff on
+// Append: a flaky broker connection is retried with exponential backoff
+// before KafkaLog gives up and returns an error to the caller.
+const (
+	kafkaMaxRetries  = 5
+	kafkaBackoffBase = 50 * time.Millisecond
+)
+
+// KafkaLog durably appends entries to a Kafka topic, keyed by RequestID so
+// all entries for a request land on the same partition and replay in
+// order. It retries transient Produce failures with exponential backoff
+// before giving up, since a broker blip shouldn't fail a signing round.
+type KafkaLog struct {
+	producer KafkaProducer
+	consumer KafkaConsumer
+	topic    string
+}
+
+// NewKafkaLog creates a KafkaLog that publishes to topic via producer and
+// replays via consumer.
+func NewKafkaLog(producer KafkaProducer, consumer KafkaConsumer, topic string) *KafkaLog {
+	return &KafkaLog{producer: producer, consumer: consumer, topic: topic}
+}
+
+func (l *KafkaLog) Append(entry LogEntry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding log entry: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt < kafkaMaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(kafkaBackoffBase * time.Duration(1<<uint(attempt-1)))
+		}
+		if err := l.producer.Produce(l.topic, []byte(entry.RequestID), value); err != nil {
+			lastErr = err
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("producing to kafka topic %s after %d attempts: %w", l.topic, kafkaMaxRetries, lastErr)
+}
+
+func (l *KafkaLog) Replay(fn func(LogEntry) error) error {
+	return l.consumer.ConsumeFromStart(l.topic, func(_, value []byte) error {
+		var entry LogEntry
+		if err := json.Unmarshal(value, &entry); err != nil {
+			return fmt.Errorf("decoding log entry: %w", err)
+		}
+		return fn(entry)
+	})
+}
+
+// Flush is a no-op: KafkaLog.Append only returns once the broker has
+// acknowledged the write, so there is nothing left to flush.
+func (l *KafkaLog) Flush() error { return nil }
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"message-board/apierr"
+	"message-board/messageboardpb"
+)
+
+const validPostMessageBody = `{"request_id":"req-1","from_node":"node-1","to_node":"node-2","round":1,"payload":"p","created_at":"2024-01-01T00:00:00Z","signature":"sig"}`
+
+// TestPostMessageSharesRejectedCodePerCaller guards the dedup introduced
+// for handlePostMessage/handlePostPresignatureMessage: both now funnel
+// through the shared postMessage helper, and this confirms each caller
+// still gets its own apierr code and logged kind instead of the two
+// blurring together.
+func TestPostMessageSharesRejectedCodePerCaller(t *testing.T) {
+	wantErr := errors.New("round 3 does not follow round 1")
+
+	cases := []struct {
+		name         string
+		post         func(context.Context, *messageboardpb.PostMessageInput) (*messageboardpb.PostMessageOutput, error)
+		rejectedCode string
+	}{
+		{"message", func(ctx context.Context, in *messageboardpb.PostMessageInput) (*messageboardpb.PostMessageOutput, error) {
+			return nil, wantErr
+		}, "message_rejected"},
+		{"presignature message", func(ctx context.Context, in *messageboardpb.PostMessageInput) (*messageboardpb.PostMessageOutput, error) {
+			return nil, wantErr
+		}, "presignature_message_rejected"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/messages", strings.NewReader(validPostMessageBody))
+			w := httptest.NewRecorder()
+
+			err := postMessage(w, r, c.name, c.rejectedCode, c.post)
+
+			var apiErr *apierr.Error
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("err = %v, want *apierr.Error", err)
+			}
+			if apiErr.Code != c.rejectedCode {
+				t.Errorf("Code = %q, want %q", apiErr.Code, c.rejectedCode)
+			}
+			if apiErr.Status != http.StatusConflict {
+				t.Errorf("Status = %d, want %d", apiErr.Status, http.StatusConflict)
+			}
+		})
+	}
+}
+
+// TestPostMessageUnauthorizedOverridesRejectedCode confirms an
+// ErrUnauthorized-wrapping post error renders as 401 regardless of which
+// caller's rejectedCode was supplied.
+func TestPostMessageUnauthorizedOverridesRejectedCode(t *testing.T) {
+	post := func(ctx context.Context, in *messageboardpb.PostMessageInput) (*messageboardpb.PostMessageOutput, error) {
+		return nil, fmt.Errorf("%w: signature does not verify", ErrUnauthorized)
+	}
+
+	r := httptest.NewRequest(http.MethodPost, "/messages", strings.NewReader(validPostMessageBody))
+	w := httptest.NewRecorder()
+
+	err := postMessage(w, r, "message", "message_rejected", post)
+
+	var apiErr *apierr.Error
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *apierr.Error", err)
+	}
+	if apiErr.Status != http.StatusUnauthorized {
+		t.Errorf("Status = %d, want %d", apiErr.Status, http.StatusUnauthorized)
+	}
+}
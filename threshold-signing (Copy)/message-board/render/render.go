@@ -0,0 +1,55 @@
+// Package render turns a handler's returned error into the JSON response
+// a MessageBoard client receives, giving every endpoint the same
+// {code, message, request_id, details} error shape instead of each
+// handler hand-rolling its own http.Error string.
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"message-board/apierr"
+)
+
+// body is the JSON shape of every rendered error.
+type body struct {
+	Code      string      `json:"code"`
+	Message   string      `json:"message"`
+	RequestID string      `json:"request_id,omitempty"`
+	Details   interface{} `json:"details,omitempty"`
+}
+
+// Error renders err as a JSON error response on w. If err (or something it
+// wraps) is an *apierr.Error, its Status and Code drive the response;
+// otherwise Error falls back to a generic 500 "internal_error" so a
+// handler that still returns a bare error degrades safely instead of
+// panicking the renderer. Either way, the request ID, status, code, and
+// the wrapped cause and stack are logged server-side and never sent to
+// the client.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	apiErr := asAPIError(err)
+	requestID := r.Header.Get("X-Request-Id")
+
+	log.Printf("api error: request_id=%s method=%s path=%s status=%d code=%s cause=%v\n%s",
+		requestID, r.Method, r.URL.Path, apiErr.Status, apiErr.Code, apiErr.Cause, apiErr.Stack)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(apiErr.Status)
+	json.NewEncoder(w).Encode(body{
+		Code:      apiErr.Code,
+		Message:   apiErr.Message,
+		RequestID: requestID,
+	})
+}
+
+// asAPIError unwraps err to find an *apierr.Error, falling back to a
+// generic 500 wrapping err verbatim if none is found.
+func asAPIError(err error) *apierr.Error {
+	var apiErr *apierr.Error
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+	return apierr.Internal("internal_error", "internal error", err)
+}
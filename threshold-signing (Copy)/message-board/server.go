@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+
+	"message-board/messageboardpb"
+)
+
+// boardServer implements messageboardpb.MessageBoard against store. It is
+// the thin adapter openapi/message_board.yaml's doc comment describes:
+// main.go's HTTP handlers decode a request into the matching
+// messageboardpb Input type, call the method here, and encode the result,
+// instead of each handler hand-rolling its own JSON glue and Store calls.
+type boardServer struct{}
+
+var _ messageboardpb.MessageBoard = boardServer{}
+
+func (boardServer) CreateRequest(ctx context.Context, in *messageboardpb.CreateRequestInput) (*messageboardpb.CreateRequestOutput, error) {
+	req, err := store.CreateRequest(ctx, in.Message, in.Scheme, in.RequestedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &messageboardpb.CreateRequestOutput{RequestID: req.ID, Status: req.Status}, nil
+}
+
+func (boardServer) GetRequest(ctx context.Context, id string) (*messageboardpb.SigningRequest, error) {
+	req := store.GetRequest(id)
+	if req == nil {
+		return nil, nil
+	}
+	return toPBSigningRequest(req), nil
+}
+
+func (boardServer) ListRequests(ctx context.Context, statusFilter string) (*messageboardpb.ListRequestsOutput, error) {
+	requests := store.ListRequests(statusFilter)
+	out := &messageboardpb.ListRequestsOutput{Requests: make([]*messageboardpb.SigningRequest, len(requests)), Count: len(requests)}
+	for i, req := range requests {
+		out.Requests[i] = toPBSigningRequest(req)
+	}
+	return out, nil
+}
+
+func (boardServer) PostMessage(ctx context.Context, in *messageboardpb.PostMessageInput) (*messageboardpb.PostMessageOutput, error) {
+	msg, err := store.PostMessage(ctx, in.RequestID, in.FromNode, in.ToNode, in.Round, in.Payload, in.CreatedAt, in.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return &messageboardpb.PostMessageOutput{MessageID: msg.ID}, nil
+}
+
+func (boardServer) GetMessages(ctx context.Context, requestID, toNode string, afterOffset int64) (*messageboardpb.GetMessagesOutput, error) {
+	messages, nextOffset := store.GetMessagesForRequest(requestID, toNode, afterOffset)
+	return toPBGetMessagesOutput(messages, nextOffset), nil
+}
+
+func (boardServer) CreatePresignatureRequest(ctx context.Context) (*messageboardpb.CreateRequestOutput, error) {
+	req, err := store.CreatePresignatureRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &messageboardpb.CreateRequestOutput{RequestID: req.ID, Status: req.Status}, nil
+}
+
+func (boardServer) PostPresignatureMessage(ctx context.Context, in *messageboardpb.PostMessageInput) (*messageboardpb.PostMessageOutput, error) {
+	msg, err := store.PostPresignatureMessage(ctx, in.RequestID, in.FromNode, in.ToNode, in.Round, in.Payload, in.CreatedAt, in.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return &messageboardpb.PostMessageOutput{MessageID: msg.ID}, nil
+}
+
+func (boardServer) GetPresignatureMessages(ctx context.Context, requestID, toNode string, afterOffset int64) (*messageboardpb.GetMessagesOutput, error) {
+	messages, nextOffset := store.GetPresignatureMessagesForRequest(requestID, toNode, afterOffset)
+	return toPBGetMessagesOutput(messages, nextOffset), nil
+}
+
+func (boardServer) PostPartialSignature(ctx context.Context, in *messageboardpb.PostPartialSignatureInput) (*messageboardpb.PartialSignatureMessage, error) {
+	sig, err := store.PostPartialSignature(ctx, in.RequestID, in.FromNode, in.Scheme, in.Payload, in.CreatedAt, in.Signature)
+	if err != nil {
+		return nil, err
+	}
+	return toPBPartialSignatureMessage(sig), nil
+}
+
+func (boardServer) GetPartialSignatures(ctx context.Context, requestID string) (*messageboardpb.GetPartialSignaturesOutput, error) {
+	sigs := store.GetPartialSignaturesForRequest(requestID)
+	out := &messageboardpb.GetPartialSignaturesOutput{PartialSignatures: make([]*messageboardpb.PartialSignatureMessage, len(sigs))}
+	for i, sig := range sigs {
+		out.PartialSignatures[i] = toPBPartialSignatureMessage(sig)
+	}
+	return out, nil
+}
+
+func (boardServer) RegisterNode(ctx context.Context, in *messageboardpb.RegisterNodeInput) error {
+	pubBytes, err := hex.DecodeString(in.PublicKey)
+	if err != nil || len(pubBytes) != ed25519.PublicKeySize {
+		return fmt.Errorf("public_key must be a hex-encoded ed25519 public key")
+	}
+	return store.RegisterNode(ctx, in.NodeID, ed25519.PublicKey(pubBytes))
+}
+
+func (boardServer) SetPublicKey(ctx context.Context, in *messageboardpb.SetPublicKeyInput) error {
+	return store.SetPublicKey(ctx, in.Scheme, in.PublicKey)
+}
+
+func (boardServer) GetPublicKey(ctx context.Context, scheme string) (*messageboardpb.GetPublicKeyOutput, error) {
+	publicKey := store.GetPublicKey(scheme)
+	if publicKey == "" {
+		return nil, nil
+	}
+	return &messageboardpb.GetPublicKeyOutput{PublicKey: publicKey}, nil
+}
+
+// toPBSigningRequest converts a Store SigningRequest to its
+// messageboardpb wire type. The field sets are identical by design (see
+// openapi/message_board.yaml); this just crosses the server/wire boundary
+// the generated types establish.
+func toPBSigningRequest(req *SigningRequest) *messageboardpb.SigningRequest {
+	return &messageboardpb.SigningRequest{
+		ID:            req.ID,
+		Message:       req.Message,
+		Scheme:        req.Scheme,
+		RequestedBy:   req.RequestedBy,
+		Status:        req.Status,
+		Signature:     req.Signature,
+		ExpectedRound: req.ExpectedRound,
+		CreatedAt:     req.CreatedAt,
+		UpdatedAt:     req.UpdatedAt,
+	}
+}
+
+func toPBNodeMessage(msg *NodeMessage) *messageboardpb.NodeMessage {
+	return &messageboardpb.NodeMessage{
+		ID:        msg.ID,
+		RequestID: msg.RequestID,
+		FromNode:  msg.FromNode,
+		ToNode:    msg.ToNode,
+		Round:     msg.Round,
+		Payload:   msg.Payload,
+		CreatedAt: msg.CreatedAt,
+		Signature: msg.Signature,
+		SignerPub: msg.SignerPub,
+	}
+}
+
+func toPBGetMessagesOutput(messages []*NodeMessage, nextOffset int64) *messageboardpb.GetMessagesOutput {
+	out := &messageboardpb.GetMessagesOutput{Messages: make([]*messageboardpb.NodeMessage, len(messages)), NextOffset: nextOffset}
+	for i, msg := range messages {
+		out.Messages[i] = toPBNodeMessage(msg)
+	}
+	return out
+}
+
+func toPBPartialSignatureMessage(sig *PartialSignatureMessage) *messageboardpb.PartialSignatureMessage {
+	return &messageboardpb.PartialSignatureMessage{
+		ID:        sig.ID,
+		RequestID: sig.RequestID,
+		FromNode:  sig.FromNode,
+		Scheme:    sig.Scheme,
+		Payload:   sig.Payload,
+		CreatedAt: sig.CreatedAt,
+		Signature: sig.Signature,
+		SignerPub: sig.SignerPub,
+	}
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// secp256k1Order is the order of the secp256k1 group, used to reduce the
+// additive combination of ECDSA partial signature scalars.
+var secp256k1Order, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+
+// partialSigPayload is the JSON shape nodes use when posting a partial
+// signature for either scheme: Index identifies the node's Shamir share
+// (needed for BLS Lagrange interpolation) and Value is the hex-encoded
+// scalar (ECDSA) or G1 point (BLS) the node computed locally.
+type partialSigPayload struct {
+	Index int    `json:"index"`
+	Value string `json:"value"`
+}
+
+// CombinePartials reconstructs the final signature for requestID from the
+// partial signatures posted so far and writes it via SetRequestSignature.
+// The combiner is selected by scheme: ECDSA partials are summed modulo the
+// secp256k1 order, BLS partials are combined by Lagrange interpolation of
+// the partial signatures (sigma_i = H(m)^{s_i}) in G1. An empty scheme
+// defaults to SchemeECDSASecp256k1. ctx's deadline bounds the call the same
+// way CreateRequest's does.
+func (s *Store) CombinePartials(ctx context.Context, requestID, scheme string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if scheme == "" {
+		scheme = SchemeECDSASecp256k1
+	}
+
+	partials := s.GetPartialSignaturesForRequest(requestID)
+	if len(partials) == 0 {
+		return fmt.Errorf("no partial signatures posted for request %s", requestID)
+	}
+
+	var signature string
+	var err error
+	switch scheme {
+	case SchemeBLS12381:
+		signature, err = combineBLSPartials(partials)
+	default:
+		signature, err = combineECDSAPartials(partials)
+	}
+	if err != nil {
+		return fmt.Errorf("combine partials for %s: %w", scheme, err)
+	}
+
+	return s.SetRequestSignature(ctx, requestID, signature)
+}
+
+// combineECDSAPartials sums the partial scalars modulo the secp256k1 order.
+// This matches CGGMP-style signing, where each partial signature is an
+// additive share of the final (r, s) scalar.
+func combineECDSAPartials(partials []*PartialSignatureMessage) (string, error) {
+	sum := new(big.Int)
+	seenFromNodes := make(map[string]bool, len(partials))
+	for _, p := range partials {
+		if p.Scheme != "" && p.Scheme != SchemeECDSASecp256k1 {
+			continue
+		}
+		// A node's share must only be summed once; a retried or duplicated
+		// POST (or a malicious resend) would otherwise silently double-count
+		// that node's contribution into the final scalar, matching the
+		// duplicate-index guard combineBLSPartials applies for the same
+		// reason.
+		if seenFromNodes[p.FromNode] {
+			return "", fmt.Errorf("partial from %s already counted toward this signature", p.FromNode)
+		}
+		raw, err := jwsPayload(p.Payload)
+		if err != nil {
+			return "", fmt.Errorf("unwrap JWS partial from %s: %w", p.FromNode, err)
+		}
+		var payload partialSigPayload
+		if err := json.Unmarshal(raw, &payload); err != nil {
+			return "", fmt.Errorf("decode partial from %s: %w", p.FromNode, err)
+		}
+		value, ok := new(big.Int).SetString(payload.Value, 16)
+		if !ok {
+			return "", fmt.Errorf("partial from %s is not a hex scalar", p.FromNode)
+		}
+		seenFromNodes[p.FromNode] = true
+		sum.Add(sum, value)
+	}
+	sum.Mod(sum, secp256k1Order)
+
+	return fmt.Sprintf("%064x", sum), nil
+}
+
+// combineBLSPartials reconstructs sigma = H(m)^s via Lagrange interpolation
+// in the exponent: sigma = sum_i lambda_i(0) * sigma_i, evaluated as a G1
+// multi-scalar multiplication so no individual share s_i is ever revealed.
+// Any t+1 of the posted partials are sufficient; every bls12381 partial
+// posted is included in the interpolation, not only t+1 of them.
+func combineBLSPartials(partials []*PartialSignatureMessage) (string, error) {
+	g1 := bls12381.NewG1()
+
+	indices := make([]int64, 0, len(partials))
+	points := make([]*bls12381.PointG1, 0, len(partials))
+	seenIndices := make(map[int64]bool, len(partials))
+	for _, p := range partials {
+		if p.Scheme != SchemeBLS12381 {
+			continue
+		}
+		jwsRaw, err := jwsPayload(p.Payload)
+		if err != nil {
+			return "", fmt.Errorf("unwrap JWS partial from %s: %w", p.FromNode, err)
+		}
+		var payload partialSigPayload
+		if err := json.Unmarshal(jwsRaw, &payload); err != nil {
+			return "", fmt.Errorf("decode partial from %s: %w", p.FromNode, err)
+		}
+		// payload.Index comes straight from the posting node and drives the
+		// Lagrange interpolation below, which requires distinct, positive
+		// share indices; a zero/negative or colliding index would otherwise
+		// zero out a denominator and crash the process (see lagrangeCoefficientAtZero).
+		if payload.Index <= 0 {
+			return "", fmt.Errorf("partial from %s has invalid index %d", p.FromNode, payload.Index)
+		}
+		index := int64(payload.Index)
+		if seenIndices[index] {
+			return "", fmt.Errorf("partial from %s duplicates share index %d already posted by another node", p.FromNode, index)
+		}
+		raw, err := hex.DecodeString(payload.Value)
+		if err != nil {
+			return "", fmt.Errorf("partial from %s is not hex: %w", p.FromNode, err)
+		}
+		point, err := g1.FromBytes(raw)
+		if err != nil {
+			return "", fmt.Errorf("partial from %s is not a valid G1 point: %w", p.FromNode, err)
+		}
+		seenIndices[index] = true
+		indices = append(indices, index)
+		points = append(points, point)
+	}
+	if len(points) == 0 {
+		return "", fmt.Errorf("no bls12381 partials available")
+	}
+
+	acc := g1.Zero()
+	for i, point := range points {
+		coeff := lagrangeCoefficientAtZero(indices, i, g1.Q())
+		scaled := g1.New()
+		g1.MulScalarBig(scaled, point, coeff)
+		g1.Add(acc, acc, scaled)
+	}
+
+	return hex.EncodeToString(g1.ToBytes(acc)), nil
+}
+
+// lagrangeCoefficientAtZero computes lambda_i(0) = prod_{j != i} (-x_j) / (x_i - x_j)
+// modulo order, for interpolating the polynomial value at x=0 from the
+// points (x_k, y_k) implied by indices.
+func lagrangeCoefficientAtZero(indices []int64, i int, order *big.Int) *big.Int {
+	num := big.NewInt(1)
+	den := big.NewInt(1)
+	xi := big.NewInt(indices[i])
+
+	for j, xj := range indices {
+		if j == i {
+			continue
+		}
+		x := big.NewInt(xj)
+		num.Mul(num, new(big.Int).Neg(x))
+		num.Mod(num, order)
+
+		diff := new(big.Int).Sub(xi, x)
+		den.Mul(den, diff)
+		den.Mod(den, order)
+	}
+
+	denInv := new(big.Int).ModInverse(den, order)
+	coeff := new(big.Int).Mul(num, denInv)
+	return coeff.Mod(coeff, order)
+}
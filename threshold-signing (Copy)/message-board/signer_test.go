@@ -0,0 +1,136 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	bls12381 "github.com/kilic/bls12-381"
+)
+
+// blsPartial builds a PartialSignatureMessage carrying a JWS-wrapped
+// partialSigPayload{index, value}, the shape combineBLSPartials expects.
+// The JWS signature itself is never checked by combineBLSPartials (that
+// happens earlier, in PostPartialSignature), so any keypair will do.
+func blsPartial(t *testing.T, fromNode string, index int, point *bls12381.PointG1) *PartialSignatureMessage {
+	t.Helper()
+	g1 := bls12381.NewG1()
+	payload, err := json.Marshal(partialSigPayload{Index: index, Value: hex.EncodeToString(g1.ToBytes(point))})
+	if err != nil {
+		t.Fatalf("marshal partialSigPayload: %v", err)
+	}
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	compact, err := SignJWS(priv, jwsProtectedHeader{Kid: fromNode, RequestID: "req-1"}, payload)
+	if err != nil {
+		t.Fatalf("SignJWS: %v", err)
+	}
+	return &PartialSignatureMessage{RequestID: "req-1", FromNode: fromNode, Scheme: SchemeBLS12381, Payload: compact}
+}
+
+// ecdsaPartial builds a PartialSignatureMessage carrying a JWS-wrapped
+// partialSigPayload{value}, the shape combineECDSAPartials expects. As with
+// blsPartial, the JWS signature itself is never checked here (that happens
+// earlier, in PostPartialSignature), so any keypair will do.
+func ecdsaPartial(t *testing.T, fromNode, value string) *PartialSignatureMessage {
+	t.Helper()
+	payload, err := json.Marshal(partialSigPayload{Value: value})
+	if err != nil {
+		t.Fatalf("marshal partialSigPayload: %v", err)
+	}
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	compact, err := SignJWS(priv, jwsProtectedHeader{Kid: fromNode, RequestID: "req-1"}, payload)
+	if err != nil {
+		t.Fatalf("SignJWS: %v", err)
+	}
+	return &PartialSignatureMessage{RequestID: "req-1", FromNode: fromNode, Scheme: SchemeECDSASecp256k1, Payload: compact}
+}
+
+// TestCombineECDSAPartialsRejectsDuplicateFromNode covers a single node's
+// partial being counted twice (a retried or duplicated POST, or a malicious
+// resend): combineECDSAPartials must reject it instead of silently summing
+// that node's share into the final scalar twice, matching the duplicate
+// guard combineBLSPartials applies for the same reason.
+func TestCombineECDSAPartialsRejectsDuplicateFromNode(t *testing.T) {
+	partials := []*PartialSignatureMessage{
+		ecdsaPartial(t, "node-1", "1"),
+		ecdsaPartial(t, "node-1", "2"),
+	}
+
+	if _, err := combineECDSAPartials(partials); err == nil {
+		t.Fatal("combineECDSAPartials accepted a duplicate from_node, want error")
+	}
+}
+
+// TestCombineECDSAPartialsValidFromNodes is the control case: distinct
+// from_nodes must still sum successfully.
+func TestCombineECDSAPartialsValidFromNodes(t *testing.T) {
+	partials := []*PartialSignatureMessage{
+		ecdsaPartial(t, "node-1", "1"),
+		ecdsaPartial(t, "node-2", "2"),
+	}
+
+	sig, err := combineECDSAPartials(partials)
+	if err != nil {
+		t.Fatalf("combineECDSAPartials rejected valid distinct from_nodes: %v", err)
+	}
+	want := fmt.Sprintf("%064x", 3)
+	if sig != want {
+		t.Fatalf("signature = %s, want %s", sig, want)
+	}
+}
+
+// TestCombineBLSPartialsRejectsInvalidIndex exercises the bug a maintainer
+// flagged: a non-positive Index used to reach lagrangeCoefficientAtZero
+// unchecked, and the only guard against a zero Lagrange denominator was
+// big.Int.ModInverse returning nil, which the very next Mul call
+// dereferenced and panicked on. combineBLSPartials must now reject the
+// partial instead of reaching that code at all.
+func TestCombineBLSPartialsRejectsInvalidIndex(t *testing.T) {
+	g1 := bls12381.NewG1()
+	partials := []*PartialSignatureMessage{
+		blsPartial(t, "node-1", 0, g1.One()),
+		blsPartial(t, "node-2", 1, g1.One()),
+	}
+
+	if _, err := combineBLSPartials(partials); err == nil {
+		t.Fatal("combineBLSPartials accepted a non-positive index, want error")
+	}
+}
+
+// TestCombineBLSPartialsRejectsDuplicateIndex covers two nodes posting the
+// same Shamir share index (e.g. both misconfigured with the default),
+// which previously zeroed the Lagrange denominator for that pair and
+// panicked instead of returning an error.
+func TestCombineBLSPartialsRejectsDuplicateIndex(t *testing.T) {
+	g1 := bls12381.NewG1()
+	partials := []*PartialSignatureMessage{
+		blsPartial(t, "node-1", 1, g1.One()),
+		blsPartial(t, "node-2", 1, g1.One()),
+	}
+
+	if _, err := combineBLSPartials(partials); err == nil {
+		t.Fatal("combineBLSPartials accepted duplicate indices, want error")
+	}
+}
+
+// TestCombineBLSPartialsValidIndices is the control case: distinct,
+// positive indices must still combine successfully.
+func TestCombineBLSPartialsValidIndices(t *testing.T) {
+	g1 := bls12381.NewG1()
+	partials := []*PartialSignatureMessage{
+		blsPartial(t, "node-1", 1, g1.One()),
+		blsPartial(t, "node-2", 2, g1.One()),
+	}
+
+	if _, err := combineBLSPartials(partials); err != nil {
+		t.Fatalf("combineBLSPartials rejected valid distinct indices: %v", err)
+	}
+}
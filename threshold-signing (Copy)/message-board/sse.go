@@ -0,0 +1,194 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"message-board/apierr"
+)
+
+// newStreamTimer starts a timer firing after streamTimeout, used by every
+// stream handler to bound how long it blocks with nothing new to send.
+func newStreamTimer() *time.Timer {
+	return time.NewTimer(streamTimeout)
+}
+
+// writeSSEEvent writes a single Server-Sent Events frame: "event: <event>"
+// followed by one "data:" line per line of the JSON encoding of payload,
+// then a blank line, and flushes so the client sees it immediately.
+func writeSSEEvent(w http.ResponseWriter, flusher http.Flusher, event string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding SSE event: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+		return err
+	}
+	flusher.Flush()
+	return nil
+}
+
+// beginSSE sets the response headers an SSE stream requires and returns the
+// Flusher every write must use, or writes an error response and returns
+// (nil, false) if the ResponseWriter doesn't support flushing.
+func beginSSE(w http.ResponseWriter) (http.Flusher, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return nil, false
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return flusher, true
+}
+
+// handleMessageStream serves GET
+// /requests/{id}/messages/stream?to_node=X&since=<msgID>, an SSE
+// alternative to handleGetMessages: if since names a message ID this
+// request has already seen, it first replays every later message in one
+// burst, then blocks (up to streamTimeout, or until the client disconnects)
+// for NodeMessages newly posted to requestID via PostMessage, emitting one
+// "message" event per NodeMessage. Subscribe is registered before the
+// backlog is read, so a message posted concurrently with the since
+// catch-up may be delivered twice (once in the backlog, once relayed) but
+// is never lost; message IDs are unique, so clients should dedupe by ID.
+func handleMessageStream(w http.ResponseWriter, r *http.Request, requestID string) {
+	toNode := r.URL.Query().Get("to_node")
+	since := r.URL.Query().Get("since")
+	messages, cancel := store.Subscribe(requestID, toNode)
+	defer cancel()
+	backlog := store.GetMessagesSinceID(requestID, toNode, since)
+
+	flusher, ok := beginSSE(w)
+	if !ok {
+		return
+	}
+
+	streamMessages(w, r, flusher, backlog, messages)
+}
+
+// handlePresignatureMessageStream is handleMessageStream for
+// PostPresignatureMessage, serving GET
+// /presignature-requests/{id}/messages/stream?to_node=X&since=<msgID>.
+// Like handleMessageStream, it subscribes before reading the backlog so a
+// concurrently posted message is at worst delivered twice, never lost.
+func handlePresignatureMessageStream(w http.ResponseWriter, r *http.Request, requestID string) {
+	toNode := r.URL.Query().Get("to_node")
+	since := r.URL.Query().Get("since")
+	messages, cancel := store.SubscribePresignatureMessages(requestID, toNode)
+	defer cancel()
+	backlog := store.GetPresignatureMessagesSinceID(requestID, toNode, since)
+
+	flusher, ok := beginSSE(w)
+	if !ok {
+		return
+	}
+
+	streamMessages(w, r, flusher, backlog, messages)
+}
+
+// streamMessages writes backlog as SSE "message" events, then relays
+// messages the same way until the client disconnects or streamTimeout
+// passes with nothing new.
+func streamMessages(w http.ResponseWriter, r *http.Request, flusher http.Flusher, backlog []*NodeMessage, messages <-chan *NodeMessage) {
+	for _, msg := range backlog {
+		if err := writeSSEEvent(w, flusher, "message", msg); err != nil {
+			return
+		}
+	}
+
+	timeout := newStreamTimer()
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timeout.C:
+			return
+		case msg := <-messages:
+			if err := writeSSEEvent(w, flusher, "message", msg); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handlePartialSignatureStream serves GET
+// /partial-signatures/stream?request_id=X, an SSE alternative to
+// handleGetPartialSignatures: it blocks (up to streamTimeout, or until the
+// client disconnects) for PartialSignatureMessages newly posted to
+// requestID via PostPartialSignature, emitting one "partial_signature"
+// event per message.
+func handlePartialSignatureStream(w http.ResponseWriter, r *http.Request) error {
+	requestID := r.URL.Query().Get("request_id")
+	if requestID == "" {
+		return apierr.BadRequest("request_id_required", "request_id parameter required", nil)
+	}
+
+	flusher, ok := beginSSE(w)
+	if !ok {
+		return nil
+	}
+
+	sigs, cancel := store.SubscribePartialSignatures(requestID)
+	defer cancel()
+
+	timeout := newStreamTimer()
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+		case <-timeout.C:
+			return nil
+		case sig := <-sigs:
+			if err := writeSSEEvent(w, flusher, "partial_signature", sig); err != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// handleStatusStream serves GET /requests/{id}/status/stream, an SSE
+// alternative to GET /requests/{id}: it blocks (up to streamTimeout, or
+// until the client disconnects) for status transitions on requestID,
+// emitting one "status" event per transition, and closes the stream once
+// it observes a terminal status ("completed" or "failed").
+func handleStatusStream(w http.ResponseWriter, r *http.Request, requestID string) {
+	flusher, ok := beginSSE(w)
+	if !ok {
+		return
+	}
+
+	statuses, cancel := store.SubscribeStatus(requestID)
+	defer cancel()
+
+	timeout := newStreamTimer()
+	defer timeout.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-timeout.C:
+			return
+		case status := <-statuses:
+			if err := writeSSEEvent(w, flusher, "status", map[string]string{
+				"request_id": requestID,
+				"status":     status,
+			}); err != nil {
+				return
+			}
+			if status == "completed" || status == "failed" {
+				return
+			}
+		}
+	}
+}
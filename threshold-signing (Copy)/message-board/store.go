@@ -1,38 +1,74 @@
 package main
 
 import (
+	"context"
+	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/hex"
 	"fmt"
+	"log"
+	"strconv"
 	"time"
 )
 
-// CreateRequest creates a new signing request with the given message
-// Returns the newly created request with a unique ID
-func (s *Store) CreateRequest(message string) (*SigningRequest, error) {
+// CreateRequest creates a new signing request with the given message and
+// scheme, optionally recording requestedBy (the API Gateway's authenticated
+// caller, empty if called directly). An empty scheme defaults to
+// SchemeECDSASecp256k1. Returns the newly created request with a unique ID.
+// ctx's deadline bounds how long the call waits on s.mu and the MessageLog
+// append; it is not threaded any deeper than that.
+func (s *Store) CreateRequest(ctx context.Context, message, scheme, requestedBy string) (*SigningRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if scheme == "" {
+		scheme = SchemeECDSASecp256k1
+	}
+
 	// Generate a unique request ID
 	id, err := generateID()
 	if err != nil {
 		return nil, err
 	}
 
-	// Create the request object
-	now := time.Now()
-	req := &SigningRequest{
-		ID:        id,
-		Message:   message,
-		Status:    "pending",
-		CreatedAt: now,
-		UpdatedAt: now,
+	entry := LogEntry{
+		Kind:        logEntryRequestCreated,
+		RequestID:   id,
+		Message:     message,
+		Scheme:      scheme,
+		RequestedBy: requestedBy,
+		CreatedAt:   time.Now(),
+	}
+	if err := s.log.Append(entry); err != nil {
+		return nil, fmt.Errorf("appending to message log: %w", err)
+	}
+	if s.auditLog != nil {
+		if _, _, err := s.auditLog.Append(auditKindRequestCreated, requestedBy, id, []byte(message)); err != nil {
+			return nil, fmt.Errorf("appending to audit log: %w", err)
+		}
 	}
 
-	// Store it
-	s.requests[id] = req
+	return s.applyRequestCreated(entry), nil
+}
 
-	return req, nil
+func (s *Store) applyRequestCreated(entry LogEntry) *SigningRequest {
+	req := &SigningRequest{
+		ID:          entry.RequestID,
+		Message:     entry.Message,
+		Scheme:      entry.Scheme,
+		RequestedBy: entry.RequestedBy,
+		Status:      "pending",
+		// Signing requests are created once keygen has already produced a
+		// shared key out of band, so they start ready for partial signatures
+		State:     StateSignPartial,
+		CreatedAt: entry.CreatedAt,
+		UpdatedAt: entry.CreatedAt,
+	}
+	s.requests[entry.RequestID] = req
+	return req
 }
 
 // GetRequest retrieves a signing request by ID
@@ -63,105 +99,306 @@ func (s *Store) ListRequests(statusFilter string) []*SigningRequest {
 }
 
 // UpdateRequestStatus updates the status of a signing request
-// Returns error if request not found
-func (s *Store) UpdateRequestStatus(id string, status string) error {
+// Returns error if request not found. ctx's deadline bounds the call the
+// same way CreateRequest's does.
+func (s *Store) UpdateRequestStatus(ctx context.Context, id string, status string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	req, exists := s.requests[id]
-	if !exists {
+	if _, exists := s.requests[id]; !exists {
 		return fmt.Errorf("request not found: %s", id)
 	}
 
-	req.Status = status
-	req.UpdatedAt = time.Now()
+	entry := LogEntry{Kind: logEntryStatusUpdate, RequestID: id, Status: status, CreatedAt: time.Now()}
+	if err := s.log.Append(entry); err != nil {
+		return fmt.Errorf("appending to message log: %w", err)
+	}
 
+	s.applyStatusUpdate(entry)
 	return nil
 }
 
-// SetRequestSignature sets the final signature for a completed request
-// Also updates status to "completed"
-func (s *Store) SetRequestSignature(id string, signature string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *Store) applyStatusUpdate(entry LogEntry) {
+	req, exists := s.requests[entry.RequestID]
+	if !exists {
+		return
+	}
+	req.Status = entry.Status
+	req.UpdatedAt = entry.CreatedAt
+	notifyStatusSubs(s.statusSubs[entry.RequestID], req.Status)
+}
+
+// GetRequestState returns the FSM state and expected round for a signing
+// request, so a node can discover what round it should post next rather
+// than polling blindly. Returns nil if the request does not exist.
+func (s *Store) GetRequestState(id string) *RequestState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
 	req, exists := s.requests[id]
 	if !exists {
+		return nil
+	}
+
+	return &RequestState{
+		RequestID:     req.ID,
+		State:         string(req.State),
+		ExpectedRound: req.ExpectedRound,
+	}
+}
+
+// SetRequestSignature sets the final signature for a completed request.
+// Also updates status to "completed". ctx's deadline bounds the call the
+// same way CreateRequest's does.
+func (s *Store) SetRequestSignature(ctx context.Context, id string, signature string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.requests[id]; !exists {
 		return fmt.Errorf("request not found: %s", id)
 	}
 
-	req.Signature = signature
-	req.Status = "completed"
-	req.UpdatedAt = time.Now()
+	entry := LogEntry{Kind: logEntrySignatureSet, RequestID: id, Signature: signature, CreatedAt: time.Now()}
+	if err := s.log.Append(entry); err != nil {
+		return fmt.Errorf("appending to message log: %w", err)
+	}
+	if s.auditLog != nil {
+		if _, _, err := s.auditLog.Append(auditKindSignatureSet, "", id, []byte(signature)); err != nil {
+			return fmt.Errorf("appending to audit log: %w", err)
+		}
+	}
 
+	s.applySignatureSet(entry)
 	return nil
 }
 
-// PostMessage stores a new message from a node
-// The message is indexed by both message ID and request ID for efficient lookup
-func (s *Store) PostMessage(requestID, fromNode, toNode string, round int, payload string) (*NodeMessage, error) {
+func (s *Store) applySignatureSet(entry LogEntry) {
+	req, exists := s.requests[entry.RequestID]
+	if !exists {
+		return
+	}
+	req.Signature = entry.Signature
+	req.Status = "completed"
+	req.State = StateCompleted
+	req.UpdatedAt = entry.CreatedAt
+	notifyStatusSubs(s.statusSubs[entry.RequestID], req.Status)
+}
+
+// RegisterNode records nodeID's ed25519 public key so the board can
+// authenticate future messages it claims to send. Re-registering with a
+// different key is rejected. Node registrations are not appended to the
+// MessageLog: a node re-registers its own key on every restart, so there is
+// nothing to replay.
+func (s *Store) RegisterNode(ctx context.Context, nodeID string, pub ed25519.PublicKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.nodeKeys.Register(nodeID, pub)
+}
+
+// RegisterNodeJWK records nodeID's JWK so the board can verify the compact
+// JWS carried in every message payload that node posts (see jws.go).
+// Re-registering with a different key is rejected. Like node identity keys,
+// JWKs are not appended to the MessageLog: a node re-registers its own JWK
+// on every restart, so there is nothing to replay.
+func (s *Store) RegisterNodeJWK(ctx context.Context, nodeID string, jwk JSONWebKey) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return s.nodeJWKs.Register(nodeID, jwk)
+}
+
+// PostMessage stores a new message from a node, durably appending it to the
+// MessageLog keyed by (RequestID, Round) before it becomes visible to
+// readers. Rejects messages whose Round does not match the request's
+// current expected round while it is progressing through the dkg_* states,
+// and rejects messages whose signature does not verify against fromNode's
+// registered key.
+func (s *Store) PostMessage(ctx context.Context, requestID, fromNode, toNode string, round int, payload string, createdAt time.Time, signature string) (*NodeMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Auto-create request if it doesn't exist (for keygen/auxgen protocols)
-	if _, exists := s.requests[requestID]; !exists {
-		// Create a placeholder request for protocol coordination
-		now := time.Now()
-		s.requests[requestID] = &SigningRequest{
-			ID:        requestID,
-			Message:   requestID, // Use request ID as message for protocol requests
-			Status:    "pending",
-			CreatedAt: now,
-			UpdatedAt: now,
+	if err := verifyNodeSignature(s.nodeKeys, fromNode, signature,
+		requestID, fromNode, toNode, strconv.Itoa(round), payload, createdAt.Format(time.RFC3339Nano)); err != nil {
+		return nil, err
+	}
+	if _, err := verifyJWS(s.nodeJWKs, payload, fromNode, requestID, &toNode, jwsRound(round)); err != nil {
+		return nil, err
+	}
+
+	req := s.requestOrPlaceholder(requestID)
+	if req.State == StateAwaitingParticipants || req.State == StateDKGCommit || req.State == StateDKGDeal || req.State == StateDKGResponse {
+		if _, err := advanceRoundState(req.State, req.ExpectedRound, round, 0, s.quorumSize); err != nil {
+			return nil, err
 		}
 	}
 
-	// Generate a unique message ID
 	id, err := generateID()
 	if err != nil {
 		return nil, err
 	}
 
-	// Create the message
-	msg := &NodeMessage{
-		ID:        id,
+	entry := LogEntry{
+		Kind:      logEntryMessage,
 		RequestID: requestID,
+		MessageID: id,
 		FromNode:  fromNode,
 		ToNode:    toNode,
 		Round:     round,
 		Payload:   payload,
-		CreatedAt: time.Now(),
+		Signature: signature,
+		SignerPub: hex.EncodeToString(s.nodeKeys.PublicKey(fromNode)),
+		CreatedAt: createdAt,
+	}
+	if err := s.log.Append(entry); err != nil {
+		return nil, fmt.Errorf("appending to message log: %w", err)
+	}
+	if s.auditLog != nil {
+		if _, _, err := s.auditLog.Append(auditKindMessage, fromNode, requestID, []byte(payload)); err != nil {
+			return nil, fmt.Errorf("appending to audit log: %w", err)
+		}
+	}
+
+	return s.applyMessage(entry), nil
+}
+
+// requestOrPlaceholder returns the existing SigningRequest for requestID,
+// auto-creating a placeholder (for keygen/auxgen protocols, which have no
+// prior CreateRequest call) if none exists yet. Callers must hold s.mu.
+func (s *Store) requestOrPlaceholder(requestID string) *SigningRequest {
+	if req, exists := s.requests[requestID]; exists {
+		return req
+	}
+	now := time.Now()
+	req := &SigningRequest{
+		ID:            requestID,
+		Message:       requestID, // Use request ID as message for protocol requests
+		Status:        "pending",
+		State:         StateAwaitingParticipants,
+		ExpectedRound: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	s.requests[requestID] = req
+	return req
+}
+
+// applyMessage applies a logEntryMessage to the in-memory cache: it stores
+// the message and advances the request's FSM state if this round now has
+// quorum. Used both for live PostMessage calls and for log replay, so it
+// must not verify signatures or touch the log itself.
+func (s *Store) applyMessage(entry LogEntry) *NodeMessage {
+	req := s.requestOrPlaceholder(entry.RequestID)
+
+	msg := &NodeMessage{
+		ID:        entry.MessageID,
+		RequestID: entry.RequestID,
+		FromNode:  entry.FromNode,
+		ToNode:    entry.ToNode,
+		Round:     entry.Round,
+		Payload:   entry.Payload,
+		CreatedAt: entry.CreatedAt,
+		Signature: entry.Signature,
+		SignerPub: entry.SignerPub,
 	}
 
-	// Store in both indexes
-	s.messages[id] = msg
-	s.messagesByRequest[requestID] = append(s.messagesByRequest[requestID], msg)
+	s.messages[msg.ID] = msg
+	s.messagesByRequest[entry.RequestID] = append(s.messagesByRequest[entry.RequestID], msg)
+	notifyMessageSubs(s.messageSubs[entry.RequestID], msg)
+
+	if req.State == StateAwaitingParticipants {
+		req.State = StateDKGCommit
+	}
+	distinct := distinctFromNodes(s.messagesByRequest[entry.RequestID], entry.Round)
+	if distinct >= s.roundQuorum() {
+		switch req.State {
+		case StateDKGCommit, StateDKGDeal, StateDKGResponse:
+			next, expectedRound, _ := nextDKGState(entry.Round)
+			req.State = next
+			req.ExpectedRound = expectedRound
+			req.UpdatedAt = entry.CreatedAt
+		}
+	}
 
-	return msg, nil
+	return msg
 }
 
-// GetMessagesForRequest retrieves all messages for a specific signing request
-// Nodes can optionally filter by recipient (toNode) to get only their messages
-func (s *Store) GetMessagesForRequest(requestID string, toNode string) []*NodeMessage {
+// GetMessagesForRequest retrieves messages for a signing request with
+// offset index greater than or equal to afterOffset, so a polling node only
+// transfers new messages instead of re-scanning the whole history every
+// call. Pass afterOffset 0 to fetch from the start. Returns the filtered
+// messages and the offset to pass as afterOffset on the next call.
+// Nodes can optionally filter by recipient (toNode) to get only their messages.
+func (s *Store) GetMessagesForRequest(requestID string, toNode string, afterOffset int64) (messages []*NodeMessage, nextOffset int64) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	allMessages := s.messagesByRequest[requestID]
-	if toNode == "" {
-		// Return all messages for this request
-		return allMessages
+	return filterMessagesAfterOffset(s.messagesByRequest[requestID], toNode, afterOffset)
+}
+
+// filterMessagesAfterOffset slices all starting at afterOffset (the
+// MessageLog/append offset within the request), then applies the toNode
+// filter. Kept separate from GetMessagesForRequest so
+// GetPresignatureMessagesForRequest can share it.
+func filterMessagesAfterOffset(all []*NodeMessage, toNode string, afterOffset int64) ([]*NodeMessage, int64) {
+	nextOffset := int64(len(all))
+	if afterOffset < 0 {
+		afterOffset = 0
+	}
+	if afterOffset >= nextOffset {
+		return nil, nextOffset
 	}
 
-	// Filter messages for specific node
-	// Include messages addressed to this node OR broadcast messages (toNode = "*")
-	var filtered []*NodeMessage
-	for _, msg := range allMessages {
-		if msg.ToNode == toNode || msg.ToNode == "*" {
-			filtered = append(filtered, msg)
+	var result []*NodeMessage
+	for _, msg := range all[afterOffset:] {
+		if toNode == "" || msg.ToNode == toNode || msg.ToNode == "*" {
+			result = append(result, msg)
 		}
 	}
+	return result, nextOffset
+}
+
+// GetMessagesSinceID returns messages for requestID posted after the
+// message with ID since (exclusive), optionally filtered to toNode. Returns
+// nil if since is empty or not found, so a caller knows to fall back to
+// live-only streaming. Used by handleMessageStream for a since=<msgID>
+// catch-up fetch before it subscribes to the live channel.
+func (s *Store) GetMessagesSinceID(requestID, toNode, since string) []*NodeMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-	return filtered
+	return messagesSinceID(s.messagesByRequest[requestID], toNode, since)
+}
+
+// GetPresignatureMessagesSinceID is GetMessagesSinceID for presignature
+// messages.
+func (s *Store) GetPresignatureMessagesSinceID(requestID, toNode, since string) []*NodeMessage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return messagesSinceID(s.presignatureMessagesByRequest[requestID], toNode, since)
+}
+
+func messagesSinceID(all []*NodeMessage, toNode, since string) []*NodeMessage {
+	if since == "" {
+		return nil
+	}
+	for i, m := range all {
+		if m.ID == since {
+			result, _ := filterMessagesAfterOffset(all, toNode, int64(i+1))
+			return result
+		}
+	}
+	return nil
 }
 
 // generateID creates a random hex-encoded ID
@@ -173,25 +410,57 @@ func generateID() (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
-// GetPublicKey returns the shared public key
+// GetPublicKey returns the shared public key for the given scheme
+// An empty scheme defaults to SchemeECDSASecp256k1
 // Returns empty string if not set yet
-func (s *Store) GetPublicKey() string {
+func (s *Store) GetPublicKey(scheme string) string {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+
+	if scheme == SchemeBLS12381 {
+		return s.blsPublicKey
+	}
 	return s.publicKey
 }
 
-// SetPublicKey stores the shared public key
+// SetPublicKey stores the shared public key for the given scheme
+// An empty scheme defaults to SchemeECDSASecp256k1
 // This should be called by a node after keygen completes
-func (s *Store) SetPublicKey(publicKey string) {
+func (s *Store) SetPublicKey(ctx context.Context, scheme, publicKey string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	s.publicKey = publicKey
+
+	entry := LogEntry{Kind: logEntryPublicKey, Scheme: scheme, Payload: publicKey, CreatedAt: time.Now()}
+	if err := s.log.Append(entry); err != nil {
+		return fmt.Errorf("appending to message log: %w", err)
+	}
+	if s.auditLog != nil {
+		if _, _, err := s.auditLog.Append(auditKindPublicKey, "", "", []byte(scheme+"|"+publicKey)); err != nil {
+			return fmt.Errorf("appending to audit log: %w", err)
+		}
+	}
+
+	s.applyPublicKey(entry)
+	return nil
+}
+
+func (s *Store) applyPublicKey(entry LogEntry) {
+	if entry.Scheme == SchemeBLS12381 {
+		s.blsPublicKey = entry.Payload
+		return
+	}
+	s.publicKey = entry.Payload
 }
 
 // CreatePresignatureRequest creates a new presignature generation request
 // Returns the newly created request with a unique ID
-func (s *Store) CreatePresignatureRequest() (*PresignatureRequest, error) {
+func (s *Store) CreatePresignatureRequest(ctx context.Context) (*PresignatureRequest, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -201,19 +470,25 @@ func (s *Store) CreatePresignatureRequest() (*PresignatureRequest, error) {
 		return nil, err
 	}
 
-	// Create the request object
-	now := time.Now()
-	req := &PresignatureRequest{
-		ID:        id,
-		Status:    "pending",
-		CreatedAt: now,
-		UpdatedAt: now,
+	entry := LogEntry{Kind: logEntryPresignatureRequestCreated, RequestID: id, CreatedAt: time.Now()}
+	if err := s.log.Append(entry); err != nil {
+		return nil, fmt.Errorf("appending to message log: %w", err)
 	}
 
-	// Store it
-	s.presignatureRequests[id] = req
+	return s.applyPresignatureRequestCreated(entry), nil
+}
 
-	return req, nil
+func (s *Store) applyPresignatureRequestCreated(entry LogEntry) *PresignatureRequest {
+	req := &PresignatureRequest{
+		ID:            entry.RequestID,
+		Status:        "pending",
+		State:         StateAwaitingParticipants,
+		ExpectedRound: 1,
+		CreatedAt:     entry.CreatedAt,
+		UpdatedAt:     entry.CreatedAt,
+	}
+	s.presignatureRequests[entry.RequestID] = req
+	return req
 }
 
 // GetPresignatureRequest retrieves a presignature request by ID
@@ -243,110 +518,279 @@ func (s *Store) ListPresignatureRequests(statusFilter string) []*PresignatureReq
 
 // UpdatePresignatureRequestStatus updates the status of a presignature request
 // Returns error if request not found
-func (s *Store) UpdatePresignatureRequestStatus(id string, status string) error {
+func (s *Store) UpdatePresignatureRequestStatus(ctx context.Context, id string, status string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	req, exists := s.presignatureRequests[id]
-	if !exists {
+	if _, exists := s.presignatureRequests[id]; !exists {
 		return fmt.Errorf("presignature request not found: %s", id)
 	}
 
-	req.Status = status
-	req.UpdatedAt = time.Now()
+	entry := LogEntry{Kind: logEntryPresignatureStatusUpdate, RequestID: id, Status: status, CreatedAt: time.Now()}
+	if err := s.log.Append(entry); err != nil {
+		return fmt.Errorf("appending to message log: %w", err)
+	}
 
+	s.applyPresignatureStatusUpdate(entry)
 	return nil
 }
 
-// PostPresignatureMessage stores a new message for presignature generation
-// The message is indexed by both message ID and request ID for efficient lookup
-func (s *Store) PostPresignatureMessage(requestID, fromNode, toNode string, round int, payload string) (*NodeMessage, error) {
+func (s *Store) applyPresignatureStatusUpdate(entry LogEntry) {
+	req, exists := s.presignatureRequests[entry.RequestID]
+	if !exists {
+		return
+	}
+	req.Status = entry.Status
+	req.UpdatedAt = entry.CreatedAt
+}
+
+// PostPresignatureMessage stores a new message for presignature generation,
+// durably appending it to the MessageLog keyed by (RequestID, Round) before
+// it becomes visible to readers. Rejects messages whose Round does not
+// match the request's current expected presign round, and rejects messages
+// whose signature does not verify against fromNode's registered key.
+func (s *Store) PostPresignatureMessage(ctx context.Context, requestID, fromNode, toNode string, round int, payload string, createdAt time.Time, signature string) (*NodeMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Auto-create presignature request if it doesn't exist
-	if _, exists := s.presignatureRequests[requestID]; !exists {
-		now := time.Now()
-		s.presignatureRequests[requestID] = &PresignatureRequest{
-			ID:        requestID,
-			Status:    "pending",
-			CreatedAt: now,
-			UpdatedAt: now,
+	if err := verifyNodeSignature(s.nodeKeys, fromNode, signature,
+		requestID, fromNode, toNode, strconv.Itoa(round), payload, createdAt.Format(time.RFC3339Nano)); err != nil {
+		return nil, err
+	}
+	if _, err := verifyJWS(s.nodeJWKs, payload, fromNode, requestID, &toNode, jwsRound(round)); err != nil {
+		return nil, err
+	}
+
+	req := s.presignatureRequestOrPlaceholder(requestID)
+	if req.State != StateCompleted && req.State != StateFailed {
+		if _, err := advanceRoundState(req.State, req.ExpectedRound, round, 0, s.quorumSize); err != nil {
+			return nil, err
 		}
 	}
 
-	// Generate a unique message ID
 	id, err := generateID()
 	if err != nil {
 		return nil, err
 	}
 
-	// Create the message
-	msg := &NodeMessage{
-		ID:        id,
+	entry := LogEntry{
+		Kind:      logEntryPresignatureMessage,
 		RequestID: requestID,
+		MessageID: id,
 		FromNode:  fromNode,
 		ToNode:    toNode,
 		Round:     round,
 		Payload:   payload,
-		CreatedAt: time.Now(),
+		Signature: signature,
+		SignerPub: hex.EncodeToString(s.nodeKeys.PublicKey(fromNode)),
+		CreatedAt: createdAt,
+	}
+	if err := s.log.Append(entry); err != nil {
+		return nil, fmt.Errorf("appending to message log: %w", err)
+	}
+	if s.auditLog != nil {
+		if _, _, err := s.auditLog.Append(auditKindPresignatureMessage, fromNode, requestID, []byte(payload)); err != nil {
+			return nil, fmt.Errorf("appending to audit log: %w", err)
+		}
+	}
+
+	return s.applyPresignatureMessage(entry), nil
+}
+
+// presignatureRequestOrPlaceholder returns the existing PresignatureRequest
+// for requestID, auto-creating a placeholder if none exists yet. Callers
+// must hold s.mu.
+func (s *Store) presignatureRequestOrPlaceholder(requestID string) *PresignatureRequest {
+	if req, exists := s.presignatureRequests[requestID]; exists {
+		return req
+	}
+	now := time.Now()
+	req := &PresignatureRequest{
+		ID:            requestID,
+		Status:        "pending",
+		State:         StateAwaitingParticipants,
+		ExpectedRound: 1,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	s.presignatureRequests[requestID] = req
+	return req
+}
+
+// applyPresignatureMessage applies a logEntryPresignatureMessage to the
+// in-memory cache, mirroring applyMessage for the presignature protocol.
+func (s *Store) applyPresignatureMessage(entry LogEntry) *NodeMessage {
+	req := s.presignatureRequestOrPlaceholder(entry.RequestID)
+
+	msg := &NodeMessage{
+		ID:        entry.MessageID,
+		RequestID: entry.RequestID,
+		FromNode:  entry.FromNode,
+		ToNode:    entry.ToNode,
+		Round:     entry.Round,
+		Payload:   entry.Payload,
+		CreatedAt: entry.CreatedAt,
+		Signature: entry.Signature,
+		SignerPub: entry.SignerPub,
 	}
 
-	// Store in presignature-specific indexes
-	s.presignatureMessages[id] = msg
-	s.presignatureMessagesByRequest[requestID] = append(s.presignatureMessagesByRequest[requestID], msg)
+	s.presignatureMessages[msg.ID] = msg
+	s.presignatureMessagesByRequest[entry.RequestID] = append(s.presignatureMessagesByRequest[entry.RequestID], msg)
+	notifyMessageSubs(s.presignatureMessageSubs[entry.RequestID], msg)
 
-	return msg, nil
+	if req.State == StateAwaitingParticipants {
+		req.State = StatePresignRound1
+	}
+	distinct := distinctFromNodes(s.presignatureMessagesByRequest[entry.RequestID], entry.Round)
+	if distinct >= s.roundQuorum() {
+		switch req.State {
+		case StatePresignRound1, StatePresignRound2, StatePresignRound3:
+			next, expectedRound, done := nextPresignState(entry.Round)
+			req.State = next
+			req.ExpectedRound = expectedRound
+			req.UpdatedAt = entry.CreatedAt
+			if done {
+				req.Status = "completed"
+			}
+		}
+	}
+
+	return msg
 }
 
-// GetPresignatureMessagesForRequest retrieves all presignature messages for a specific request
-// Nodes can optionally filter by recipient (toNode) to get only their messages
-func (s *Store) GetPresignatureMessagesForRequest(requestID string, toNode string) []*NodeMessage {
+// GetPresignatureMessagesForRequest retrieves presignature messages for a
+// request with offset index greater than or equal to afterOffset; see
+// GetMessagesForRequest for the offset contract.
+// Nodes can optionally filter by recipient (toNode) to get only their messages.
+func (s *Store) GetPresignatureMessagesForRequest(requestID string, toNode string, afterOffset int64) (messages []*NodeMessage, nextOffset int64) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	allMessages := s.presignatureMessagesByRequest[requestID]
-	if toNode == "" {
-		return allMessages
+	return filterMessagesAfterOffset(s.presignatureMessagesByRequest[requestID], toNode, afterOffset)
+}
+
+// PostPartialSignature posts a partial signature for a signing request,
+// durably appending it to the MessageLog before it becomes visible to
+// readers. An empty scheme defaults to SchemeECDSASecp256k1. Rejects
+// partial signatures whose signature does not verify against fromNode's
+// registered key. Once s.roundQuorum() distinct nodes have posted a partial
+// signature for requestID under scheme, it combines them via
+// CombinePartials and sets the request's final signature; a combine
+// failure (e.g. a malformed partial) is logged but does not fail this
+// call, since the partial signature itself was still accepted. Returns the
+// created PartialSignatureMessage.
+func (s *Store) PostPartialSignature(ctx context.Context, requestID, fromNode, scheme, payload string, createdAt time.Time, signature string) (*PartialSignatureMessage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if scheme == "" {
+		scheme = SchemeECDSASecp256k1
+	}
+
+	partialSig, readyToCombine, err := s.postPartialSignatureLocked(requestID, fromNode, scheme, payload, createdAt, signature)
+	if err != nil {
+		return nil, err
 	}
 
-	// Filter messages for specific node
-	var filtered []*NodeMessage
-	for _, msg := range allMessages {
-		if msg.ToNode == toNode || msg.ToNode == "*" {
-			filtered = append(filtered, msg)
+	if readyToCombine {
+		if err := s.CombinePartials(ctx, requestID, scheme); err != nil {
+			log.Printf("auto-combine partial signatures for %s (%s): %v", requestID, scheme, err)
 		}
 	}
 
-	return filtered
+	return partialSig, nil
 }
 
-// PostPartialSignature posts a partial signature for a signing request
-// Returns the created PartialSignatureMessage
-func (s *Store) PostPartialSignature(requestID, fromNode, payload string) (*PartialSignatureMessage, error) {
+// postPartialSignatureLocked does the locked work of PostPartialSignature
+// and reports whether requestID now has s.roundQuorum() distinct partials
+// posted for scheme and isn't already completed, so the caller can call
+// CombinePartials after releasing s.mu (CombinePartials takes it itself).
+func (s *Store) postPartialSignatureLocked(requestID, fromNode, scheme, payload string, createdAt time.Time, signature string) (partialSig *PartialSignatureMessage, readyToCombine bool, err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	// Generate a unique ID for this partial signature
+	if err := verifyNodeSignature(s.nodeKeys, fromNode, signature,
+		requestID, fromNode, payload, createdAt.Format(time.RFC3339Nano)); err != nil {
+		return nil, false, err
+	}
+	if _, err := verifyJWS(s.nodeJWKs, payload, fromNode, requestID, nil, nil); err != nil {
+		return nil, false, err
+	}
+
 	id, err := generateID()
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
-	// Create the partial signature message
-	partialSig := &PartialSignatureMessage{
-		ID:        id,
+	entry := LogEntry{
+		Kind:      logEntryPartialSignature,
 		RequestID: requestID,
+		MessageID: id,
 		FromNode:  fromNode,
+		Scheme:    scheme,
 		Payload:   payload,
-		CreatedAt: time.Now(),
+		Signature: signature,
+		SignerPub: hex.EncodeToString(s.nodeKeys.PublicKey(fromNode)),
+		CreatedAt: createdAt,
+	}
+	if err := s.log.Append(entry); err != nil {
+		return nil, false, fmt.Errorf("appending to message log: %w", err)
+	}
+	if s.auditLog != nil {
+		if _, _, err := s.auditLog.Append(auditKindPartialSignature, fromNode, requestID, []byte(payload)); err != nil {
+			return nil, false, fmt.Errorf("appending to audit log: %w", err)
+		}
 	}
 
-	// Store it
-	s.partialSignatures[id] = partialSig
-	s.partialSignaturesByRequest[requestID] = append(s.partialSignaturesByRequest[requestID], partialSig)
+	partialSig = s.applyPartialSignature(entry)
 
-	return partialSig, nil
+	req := s.requests[requestID]
+	readyToCombine = req != nil && req.Status != "completed" &&
+		distinctPartialSigners(s.partialSignaturesByRequest[requestID], scheme) >= s.roundQuorum()
+
+	return partialSig, readyToCombine, nil
+}
+
+func (s *Store) applyPartialSignature(entry LogEntry) *PartialSignatureMessage {
+	partialSig := &PartialSignatureMessage{
+		ID:        entry.MessageID,
+		RequestID: entry.RequestID,
+		FromNode:  entry.FromNode,
+		Scheme:    entry.Scheme,
+		Payload:   entry.Payload,
+		CreatedAt: entry.CreatedAt,
+		Signature: entry.Signature,
+		SignerPub: entry.SignerPub,
+	}
+
+	s.partialSignatures[partialSig.ID] = partialSig
+	s.partialSignaturesByRequest[entry.RequestID] = append(s.partialSignaturesByRequest[entry.RequestID], partialSig)
+	notifyPartialSignatureSubs(s.partialSignatureSubs[entry.RequestID], partialSig)
+
+	return partialSig
+}
+
+// distinctPartialSigners counts the distinct FromNode values among partials
+// whose Scheme matches scheme (partials posted before schemes existed have
+// an empty Scheme, which is treated as SchemeECDSASecp256k1).
+func distinctPartialSigners(partials []*PartialSignatureMessage, scheme string) int {
+	seen := make(map[string]struct{})
+	for _, p := range partials {
+		partialScheme := p.Scheme
+		if partialScheme == "" {
+			partialScheme = SchemeECDSASecp256k1
+		}
+		if partialScheme == scheme {
+			seen[p.FromNode] = struct{}{}
+		}
+	}
+	return len(seen)
 }
 
 // GetPartialSignaturesForRequest retrieves all partial signatures for a signing request
@@ -356,3 +800,74 @@ func (s *Store) GetPartialSignaturesForRequest(requestID string) []*PartialSigna
 
 	return s.partialSignaturesByRequest[requestID]
 }
+
+// AuditSTH returns the board's current SignedTreeHead. ok is false if no
+// audit log was configured (see MPC_BOARD_AUDIT_LOG_PATH).
+func (s *Store) AuditSTH() (sth SignedTreeHead, ok bool) {
+	if s.auditLog == nil {
+		return SignedTreeHead{}, false
+	}
+	return s.auditLog.STH(), true
+}
+
+// AuditInclusionProof returns the index and Merkle audit path proving
+// leafHash is included in the first treeSize audit log entries. Returns an
+// error if no audit log was configured, treeSize is out of range, or
+// leafHash is not found.
+func (s *Store) AuditInclusionProof(leafHash []byte, treeSize int64) (index int64, path [][]byte, err error) {
+	if s.auditLog == nil {
+		return 0, nil, fmt.Errorf("audit log not configured")
+	}
+	return s.auditLog.InclusionProof(leafHash, treeSize)
+}
+
+// AuditConsistencyProof returns the Merkle consistency proof between two
+// previously-published audit log tree sizes. Returns an error if no audit
+// log was configured or either tree size is out of range.
+func (s *Store) AuditConsistencyProof(first, second int64) ([][]byte, error) {
+	if s.auditLog == nil {
+		return nil, fmt.Errorf("audit log not configured")
+	}
+	return s.auditLog.ConsistencyProof(first, second)
+}
+
+// replay rebuilds requests, presignatureRequests, messagesByRequest,
+// presignatureMessagesByRequest, partialSignaturesByRequest, publicKey, and
+// blsPublicKey from s.log by re-applying every previously-appended entry in
+// order. Called once from NewStoreWithLog, before the Store is shared with
+// any other goroutine, so it does not take s.mu.
+func (s *Store) replay() error {
+	return s.log.Replay(func(entry LogEntry) error {
+		switch entry.Kind {
+		case logEntryRequestCreated:
+			s.applyRequestCreated(entry)
+		case logEntryStatusUpdate:
+			s.applyStatusUpdate(entry)
+		case logEntrySignatureSet:
+			s.applySignatureSet(entry)
+		case logEntryMessage:
+			s.applyMessage(entry)
+		case logEntryPresignatureRequestCreated:
+			s.applyPresignatureRequestCreated(entry)
+		case logEntryPresignatureStatusUpdate:
+			s.applyPresignatureStatusUpdate(entry)
+		case logEntryPresignatureMessage:
+			s.applyPresignatureMessage(entry)
+		case logEntryPartialSignature:
+			s.applyPartialSignature(entry)
+		case logEntryPublicKey:
+			s.applyPublicKey(entry)
+		default:
+			return fmt.Errorf("unknown message log entry kind %q", entry.Kind)
+		}
+		return nil
+	})
+}
+
+// Flush blocks until every Store mutation that has returned to its caller
+// is durable in the MessageLog. Nothing in the board or the API gateway
+// calls it: no HTTP endpoint exposes it, and the gateway acknowledges a sign
+// request without a flush.
+func (s *Store) Flush() error {
+	return s.log.Flush()
+}
@@ -0,0 +1,171 @@
+package main
+
+// subscriberBufferSize bounds how many not-yet-delivered events a slow
+// subscriber (an SSE client reading behind a laggy connection) can fall
+// behind by. Fan-out happens synchronously from inside PostMessage et al.
+// while s.mu is held, so a send must never block; once a subscriber's
+// channel is full, further events for it are dropped rather than stalling
+// every other node waiting on the same lock.
+const subscriberBufferSize = 16
+
+// messageSub is one call to Store.Subscribe or
+// Store.SubscribePresignatureMessages, optionally filtered to a single
+// recipient.
+type messageSub struct {
+	toNode string
+	ch     chan *NodeMessage
+}
+
+// partialSignatureSub is one call to Store.SubscribePartialSignatures.
+type partialSignatureSub struct {
+	ch chan *PartialSignatureMessage
+}
+
+// statusSub is one call to Store.SubscribeStatus.
+type statusSub struct {
+	ch chan string
+}
+
+// Subscribe registers for NodeMessages posted to requestID via PostMessage,
+// optionally filtered to toNode (pass "" to receive every recipient,
+// including broadcasts). The returned channel receives one message per
+// matching PostMessage call; callers must not assume every message is
+// delivered, since once a subscriber is subscriberBufferSize messages
+// behind, newer messages for it are silently dropped rather than blocking
+// PostMessage. Call
+// cancel when done listening; it closes the channel and removes the
+// subscription.
+//
+// Subscribe only observes messages posted after it is called. A caller that
+// also wants the backlog should fetch it with GetMessagesForRequest before
+// or after subscribing and dedupe by message ID, since a message posted
+// concurrently with that fetch may arrive both ways.
+func (s *Store) Subscribe(requestID, toNode string) (<-chan *NodeMessage, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &messageSub{toNode: toNode, ch: make(chan *NodeMessage, subscriberBufferSize)}
+	s.messageSubs[requestID] = append(s.messageSubs[requestID], sub)
+
+	return sub.ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.messageSubs[requestID] = removeMessageSub(s.messageSubs[requestID], sub)
+		close(sub.ch)
+	}
+}
+
+// SubscribePresignatureMessages is Subscribe for PostPresignatureMessage.
+func (s *Store) SubscribePresignatureMessages(requestID, toNode string) (<-chan *NodeMessage, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &messageSub{toNode: toNode, ch: make(chan *NodeMessage, subscriberBufferSize)}
+	s.presignatureMessageSubs[requestID] = append(s.presignatureMessageSubs[requestID], sub)
+
+	return sub.ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.presignatureMessageSubs[requestID] = removeMessageSub(s.presignatureMessageSubs[requestID], sub)
+		close(sub.ch)
+	}
+}
+
+// SubscribePartialSignatures registers for PartialSignatureMessages posted
+// to requestID via PostPartialSignature; see Subscribe for delivery and
+// backlog caveats.
+func (s *Store) SubscribePartialSignatures(requestID string) (<-chan *PartialSignatureMessage, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &partialSignatureSub{ch: make(chan *PartialSignatureMessage, subscriberBufferSize)}
+	s.partialSignatureSubs[requestID] = append(s.partialSignatureSubs[requestID], sub)
+
+	return sub.ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.partialSignatureSubs[requestID] = removePartialSignatureSub(s.partialSignatureSubs[requestID], sub)
+		close(sub.ch)
+	}
+}
+
+// SubscribeStatus registers for status transitions on the signing request
+// requestID, as applied by UpdateRequestStatus and SetRequestSignature; see
+// Subscribe for delivery and backlog caveats.
+func (s *Store) SubscribeStatus(requestID string) (<-chan string, func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sub := &statusSub{ch: make(chan string, subscriberBufferSize)}
+	s.statusSubs[requestID] = append(s.statusSubs[requestID], sub)
+
+	return sub.ch, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.statusSubs[requestID] = removeStatusSub(s.statusSubs[requestID], sub)
+		close(sub.ch)
+	}
+}
+
+// notifyMessageSubs fans msg out to every subscriber on subs whose toNode
+// filter matches, in a non-blocking send. Callers must hold s.mu.
+func notifyMessageSubs(subs []*messageSub, msg *NodeMessage) {
+	for _, sub := range subs {
+		if sub.toNode != "" && sub.toNode != msg.ToNode && msg.ToNode != "*" {
+			continue
+		}
+		select {
+		case sub.ch <- msg:
+		default:
+		}
+	}
+}
+
+// notifyPartialSignatureSubs fans sig out to every subscriber on subs in a
+// non-blocking send. Callers must hold s.mu.
+func notifyPartialSignatureSubs(subs []*partialSignatureSub, sig *PartialSignatureMessage) {
+	for _, sub := range subs {
+		select {
+		case sub.ch <- sig:
+		default:
+		}
+	}
+}
+
+// notifyStatusSubs fans status out to every subscriber on subs in a
+// non-blocking send. Callers must hold s.mu.
+func notifyStatusSubs(subs []*statusSub, status string) {
+	for _, sub := range subs {
+		select {
+		case sub.ch <- status:
+		default:
+		}
+	}
+}
+
+func removeMessageSub(subs []*messageSub, target *messageSub) []*messageSub {
+	for i, sub := range subs {
+		if sub == target {
+			return append(subs[:i], subs[i+1:]...)
+		}
+	}
+	return subs
+}
+
+func removePartialSignatureSub(subs []*partialSignatureSub, target *partialSignatureSub) []*partialSignatureSub {
+	for i, sub := range subs {
+		if sub == target {
+			return append(subs[:i], subs[i+1:]...)
+		}
+	}
+	return subs
+}
+
+func removeStatusSub(subs []*statusSub, target *statusSub) []*statusSub {
+	for i, sub := range subs {
+		if sub == target {
+			return append(subs[:i], subs[i+1:]...)
+		}
+	}
+	return subs
+}
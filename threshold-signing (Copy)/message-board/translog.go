@@ -0,0 +1,349 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// Audit log entry kinds, one per Store mutation the transparency log
+// covers. Node registration (RegisterNode/RegisterNodeJWK) is deliberately
+// excluded, matching MessageLog: a node re-registers its own key on every
+// restart, so there is nothing worth making tamper-evident.
+const (
+	auditKindRequestCreated      = "request_created"
+	auditKindMessage             = "message"
+	auditKindPresignatureMessage = "presignature_message"
+	auditKindPartialSignature    = "partial_signature"
+	auditKindSignatureSet        = "signature_set"
+	auditKindPublicKey           = "public_key"
+)
+
+// defaultSTHGossipInterval is how often gossipSTH posts the current STH to
+// MPC_BOARD_STH_WEBHOOK_URL when no MPC_BOARD_STH_GOSSIP_INTERVAL is set.
+const defaultSTHGossipInterval = 60 * time.Second
+
+// RFC 6962 domain-separation prefixes: prepended before hashing so a leaf
+// hash can never collide with an internal node hash over the same bytes.
+const (
+	merkleLeafPrefix = 0x00
+	merkleNodePrefix = 0x01
+)
+
+// AuditLeaf is the canonical JSON record TransparencyLog.Append durably
+// writes for one Store mutation and hashes into the Merkle tree. Fields are
+// never omitted based on the entry's Kind (unlike LogEntry), so the exact
+// same bytes are produced for the same mutation everywhere; PayloadHash
+// stands in for the mutation's payload rather than the payload itself, so
+// the audit log doesn't duplicate message contents MessageLog already
+// holds durably.
+type AuditLeaf struct {
+	Seq         int64     `json:"seq"`
+	Ts          time.Time `json:"ts"`
+	Kind        string    `json:"kind"`
+	Actor       string    `json:"actor"`
+	RequestID   string    `json:"request_id"`
+	PayloadHash string    `json:"payload_hash"`
+}
+
+// SignedTreeHead is the board's signed commitment to the transparency
+// log's state at a point in time, served from GET /log/sth and gossiped
+// periodically to MPC_BOARD_STH_WEBHOOK_URL so an external auditor doesn't
+// have to trust the board to notice if it ever rewrites history.
+type SignedTreeHead struct {
+	TreeSize  int64     `json:"tree_size"`
+	RootHash  string    `json:"root_hash"`
+	Timestamp time.Time `json:"timestamp"`
+	Signature string    `json:"signature"`
+}
+
+// TransparencyLog is an append-only, tamper-evident audit trail of Store
+// mutations: every Append durably writes one AuditLeaf to disk and folds
+// its hash into an in-memory RFC 6962 Merkle tree, so a node or external
+// auditor can later ask for an inclusion proof (was this leaf recorded?)
+// or a consistency proof (did every leaf in an earlier tree survive into a
+// later one?) without trusting the board's word for it.
+type TransparencyLog struct {
+	mu         sync.Mutex
+	file       *os.File
+	leafHashes [][]byte
+	signingKey ed25519.PrivateKey
+}
+
+// NewTransparencyLog opens (creating if necessary) the JSON-lines audit log
+// at path and replays any entries already in it to rebuild the in-memory
+// Merkle tree, so a restarting MessageBoard's STH picks up where the
+// previous process left off. signingKey signs every STH this log produces.
+func NewTransparencyLog(path string, signingKey ed25519.PrivateKey) (*TransparencyLog, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("opening audit log %s: %w", path, err)
+	}
+
+	t := &TransparencyLog{file: f, signingKey: signingKey}
+	if err := t.replay(); err != nil {
+		return nil, fmt.Errorf("replaying audit log %s: %w", path, err)
+	}
+	return t, nil
+}
+
+// replay rebuilds t.leafHashes from every line already in t.file. Called
+// once from NewTransparencyLog, before the log is shared with any other
+// goroutine, so it does not take t.mu.
+func (t *TransparencyLog) replay() error {
+	if _, err := t.file.Seek(0, 0); err != nil {
+		return fmt.Errorf("seeking audit log: %w", err)
+	}
+
+	scanner := bufio.NewScanner(t.file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := append([]byte(nil), scanner.Bytes()...)
+		t.leafHashes = append(t.leafHashes, hashLeaf(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading audit log: %w", err)
+	}
+
+	// Resume writing at the end after replay.
+	_, err := t.file.Seek(0, 2)
+	return err
+}
+
+// Append records one mutation: kind identifies the Store call, actor is the
+// node or subject responsible for it (empty if not applicable), requestID
+// ties it to a SigningRequest/PresignatureRequest (empty if not
+// applicable), and payload is hashed, never stored verbatim. Returns the
+// new leaf's hash (as fed into the Merkle tree) and its sequence number.
+func (t *TransparencyLog) Append(kind, actor, requestID string, payload []byte) (leafHash []byte, seq int64, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sum := sha256.Sum256(payload)
+	leaf := AuditLeaf{
+		Seq:         int64(len(t.leafHashes)),
+		Ts:          time.Now(),
+		Kind:        kind,
+		Actor:       actor,
+		RequestID:   requestID,
+		PayloadHash: hex.EncodeToString(sum[:]),
+	}
+
+	line, err := json.Marshal(leaf)
+	if err != nil {
+		return nil, 0, fmt.Errorf("encoding audit leaf: %w", err)
+	}
+	line = append(line, '\n')
+
+	if _, err := t.file.Write(line); err != nil {
+		return nil, 0, fmt.Errorf("writing audit leaf: %w", err)
+	}
+	if err := t.file.Sync(); err != nil {
+		return nil, 0, fmt.Errorf("syncing audit log: %w", err)
+	}
+
+	h := hashLeaf(line)
+	t.leafHashes = append(t.leafHashes, h)
+	return h, leaf.Seq, nil
+}
+
+// STH returns the board's current SignedTreeHead, computed over every leaf
+// appended so far and signed with t.signingKey.
+func (t *TransparencyLog) STH() SignedTreeHead {
+	t.mu.Lock()
+	size := int64(len(t.leafHashes))
+	root := merkleRoot(t.leafHashes)
+	t.mu.Unlock()
+
+	sth := SignedTreeHead{
+		TreeSize:  size,
+		RootHash:  hex.EncodeToString(root),
+		Timestamp: time.Now(),
+	}
+	sth.Signature = hex.EncodeToString(ed25519.Sign(t.signingKey, sthSigningInput(sth)))
+	return sth
+}
+
+// sthSigningInput is the byte string signed over for a SignedTreeHead,
+// joining its fields with "|". Unlike NodeMessage/PartialSignatureMessage
+// signatures, which sign signedParts' JSON array, no field here can contain
+// "|": TreeSize is an integer, RootHash is hex, and Timestamp is RFC3339Nano.
+func sthSigningInput(sth SignedTreeHead) []byte {
+	return []byte(fmt.Sprintf("%d|%s|%s", sth.TreeSize, sth.RootHash, sth.Timestamp.Format(time.RFC3339Nano)))
+}
+
+// InclusionProof returns the index and RFC 6962 Merkle audit path proving
+// that the leaf hashing to leafHash is included in the first treeSize
+// leaves appended so far. Returns an error if treeSize is out of range or
+// no appended leaf (within the first treeSize) hashes to leafHash.
+func (t *TransparencyLog) InclusionProof(leafHash []byte, treeSize int64) (index int64, path [][]byte, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if treeSize <= 0 || treeSize > int64(len(t.leafHashes)) {
+		return 0, nil, fmt.Errorf("tree_size %d out of range [1, %d]", treeSize, len(t.leafHashes))
+	}
+
+	index = -1
+	for i := int64(0); i < treeSize; i++ {
+		if bytes.Equal(t.leafHashes[i], leafHash) {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return 0, nil, fmt.Errorf("leaf hash %x not found in the first %d leaves", leafHash, treeSize)
+	}
+
+	return index, inclusionPath(t.leafHashes[:treeSize], index), nil
+}
+
+// ConsistencyProof returns the RFC 6962 consistency proof between the
+// first and second previously-published tree sizes, so an auditor who
+// recorded an earlier STH can confirm every leaf it covered is still
+// present, in the same order, in a later one.
+func (t *TransparencyLog) ConsistencyProof(first, second int64) ([][]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if first < 0 || second < first || second > int64(len(t.leafHashes)) {
+		return nil, fmt.Errorf("invalid tree sizes %d, %d (current size %d)", first, second, len(t.leafHashes))
+	}
+	if first == 0 || first == second {
+		return nil, nil
+	}
+
+	return subProof(t.leafHashes[:second], first, true), nil
+}
+
+// hashLeaf computes RFC 6962's leaf hash: SHA-256(0x00 || data).
+func hashLeaf(data []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{merkleLeafPrefix})
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+// hashChildren computes RFC 6962's internal node hash:
+// SHA-256(0x01 || left || right).
+func hashChildren(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{merkleNodePrefix})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// largestPowerOfTwoLessThan returns the largest power of two strictly less
+// than n, the "k" split RFC 6962 uses to recurse MTH, PATH, and PROOF; n
+// must be >= 2.
+func largestPowerOfTwoLessThan(n int64) int64 {
+	k := int64(1)
+	for k*2 < n {
+		k *= 2
+	}
+	return k
+}
+
+// merkleRoot computes RFC 6962's MTH(leafHashes): the root hash of the
+// Merkle tree over an already-hashed leaf list. leafHashes[i] must be
+// hashLeaf's output, not raw leaf bytes.
+func merkleRoot(leafHashes [][]byte) []byte {
+	n := int64(len(leafHashes))
+	switch {
+	case n == 0:
+		empty := sha256.Sum256(nil)
+		return empty[:]
+	case n == 1:
+		return leafHashes[0]
+	default:
+		k := largestPowerOfTwoLessThan(n)
+		return hashChildren(merkleRoot(leafHashes[:k]), merkleRoot(leafHashes[k:]))
+	}
+}
+
+// inclusionPath computes RFC 6962's PATH(m, D[n]): the audit path proving
+// the leaf at index m belongs to the tree over leafHashes.
+func inclusionPath(leafHashes [][]byte, m int64) [][]byte {
+	n := int64(len(leafHashes))
+	if n <= 1 {
+		return nil
+	}
+	k := largestPowerOfTwoLessThan(n)
+	if m < k {
+		return append(inclusionPath(leafHashes[:k], m), merkleRoot(leafHashes[k:]))
+	}
+	return append(inclusionPath(leafHashes[k:], m-k), merkleRoot(leafHashes[:k]))
+}
+
+// subProof computes RFC 6962's SUBPROOF(m, D[n], b), the shared recursion
+// behind PROOF(m, D[n]): b is true on the outermost call and stays true
+// until the recursion first takes the right-hand branch. At the m == n base
+// case, MTH(d) is added only when b is false; with b still true, d is the
+// old tree itself and nothing is added (first == second never reaches here,
+// as ConsistencyProof handles it).
+func subProof(d [][]byte, m int64, b bool) [][]byte {
+	n := int64(len(d))
+	if m == n {
+		if b {
+			return nil
+		}
+		return [][]byte{merkleRoot(d)}
+	}
+
+	k := largestPowerOfTwoLessThan(n)
+	if m <= k {
+		return append(subProof(d[:k], m, b), merkleRoot(d[k:]))
+	}
+	return append(subProof(d[k:], m-k, false), merkleRoot(d[:k]))
+}
+
+// loadBoardSigningKey reads a hex-encoded ed25519 seed from path (see
+// MPC_BOARD_SIGNING_KEY_PATH) and expands it into the private key used to
+// sign every SignedTreeHead this board publishes.
+func loadBoardSigningKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	seed, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%s must contain a hex-encoded %d-byte ed25519 seed", path, ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// gossipSTH posts log's current SignedTreeHead to webhookURL every
+// interval, so external auditors don't have to poll GET /log/sth
+// themselves to notice the board has published a new one. Logs (rather
+// than fails) a webhook error, since a gossip hiccup shouldn't take the
+// board down.
+func gossipSTH(auditLog *TransparencyLog, webhookURL string, interval time.Duration) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		sth := auditLog.STH()
+		body, err := json.Marshal(sth)
+		if err != nil {
+			log.Printf("gossiping STH: encoding: %v", err)
+			continue
+		}
+		resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(body))
+		if err != nil {
+			log.Printf("gossiping STH to %s: %v", webhookURL, err)
+			continue
+		}
+		resp.Body.Close()
+	}
+}
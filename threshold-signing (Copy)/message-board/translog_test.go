@@ -0,0 +1,208 @@
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+)
+
+// rootFromInclusionProof independently reconstructs MTH(D[0:n]) from a
+// single leaf hash, its index, and inclusionPath's output, by retracing
+// inclusionPath's own recursion in reverse: the proof element appended
+// last (by inclusionPath's `append(recurse(...), sibling)`) is the
+// outermost split's sibling, so it is consumed first here, innermost
+// last. This is the mathematical inverse of inclusionPath, not a separate
+// reimplementation of RFC 6962, but it still catches regressions in
+// inclusionPath's branch/ordering logic: a bug there reaches a tree the
+// same leaves and index can no longer be reconstructed into.
+func rootFromInclusionProof(leafHash []byte, m, n int64, proof [][]byte) []byte {
+	if n == 1 {
+		return leafHash
+	}
+	k := largestPowerOfTwoLessThan(n)
+	sibling := proof[len(proof)-1]
+	rest := proof[:len(proof)-1]
+	if m < k {
+		return hashChildren(rootFromInclusionProof(leafHash, m, k, rest), sibling)
+	}
+	return hashChildren(sibling, rootFromInclusionProof(leafHash, m-k, n-k, rest))
+}
+
+func testLeafHashes(n int) [][]byte {
+	hashes := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		hashes[i] = hashLeaf([]byte{byte(i), byte(i), byte(i)})
+	}
+	return hashes
+}
+
+// TestMerkleRootMatchesHandDerivedStructure independently checks
+// merkleRoot for a tree size (5) whose split isn't a clean power of two,
+// combining leaf hashes by hand via hashChildren in the shape RFC 6962's
+// MTH recursion implies (k = largest power of two < n = 4 for n=5), so a
+// bug in largestPowerOfTwoLessThan or the split itself would show up as a
+// mismatch here even though rootFromInclusionProof below reuses the same
+// helper.
+func TestMerkleRootMatchesHandDerivedStructure(t *testing.T) {
+	h := testLeafHashes(5)
+	want := hashChildren(
+		hashChildren(hashChildren(h[0], h[1]), hashChildren(h[2], h[3])),
+		h[4],
+	)
+	if got := merkleRoot(h); string(got) != string(want) {
+		t.Fatalf("merkleRoot(5 leaves) = %x, want %x", got, want)
+	}
+}
+
+// TestInclusionProofRoundTrips builds trees of every size from 1 to 8 and,
+// for every leaf index, confirms inclusionPath's proof reconstructs
+// exactly the same root merkleRoot computes directly over the whole leaf
+// set — the property InclusionProof exists to let a client check without
+// holding every leaf itself.
+func TestInclusionProofRoundTrips(t *testing.T) {
+	for n := 1; n <= 8; n++ {
+		h := testLeafHashes(n)
+		root := merkleRoot(h)
+		for m := 0; m < n; m++ {
+			proof := inclusionPath(h, int64(m))
+			got := rootFromInclusionProof(h[m], int64(m), int64(n), proof)
+			if string(got) != string(root) {
+				t.Fatalf("n=%d m=%d: reconstructed root = %x, want %x", n, m, got, root)
+			}
+		}
+	}
+}
+
+// TestInclusionProofRejectsTamperedLeaf confirms a proof computed for one
+// leaf does not also validate a different leaf hash at the same index —
+// i.e. the proof is bound to the specific leaf, not just its position.
+func TestInclusionProofRejectsTamperedLeaf(t *testing.T) {
+	h := testLeafHashes(6)
+	root := merkleRoot(h)
+	proof := inclusionPath(h, 2)
+
+	forged := hashLeaf([]byte("not the real leaf"))
+	got := rootFromInclusionProof(forged, 2, 6, proof)
+	if string(got) == string(root) {
+		t.Fatal("a forged leaf reconstructed the genuine root via another leaf's proof")
+	}
+}
+
+// TestConsistencyProofPowerOfTwo exercises ConsistencyProof's simplest
+// case: growing from a tree whose size is already a power of two (2) to a
+// slightly larger one (3). The proof must let a client holding only the
+// old root (not the old leaves) derive the new root by combining it with
+// the one new leaf's hash.
+func TestConsistencyProofPowerOfTwo(t *testing.T) {
+	h := testLeafHashes(3)
+	oldRoot := merkleRoot(h[:2])
+	newRoot := merkleRoot(h[:3])
+
+	proof := subProof(h[:3], 2, true)
+	if len(proof) != 1 {
+		t.Fatalf("len(proof) = %d, want 1", len(proof))
+	}
+	if string(proof[0]) != string(h[2]) {
+		t.Fatalf("proof[0] = %x, want the new leaf hash %x", proof[0], h[2])
+	}
+	if got := hashChildren(oldRoot, proof[0]); string(got) != string(newRoot) {
+		t.Fatalf("hashChildren(oldRoot, proof[0]) = %x, want new root %x", got, newRoot)
+	}
+}
+
+// TestConsistencyProofNonPowerOfTwo exercises the branch of subProof
+// reached when the old tree size (3) is not itself a power of two,
+// growing it to 4 leaves. Traced by hand against subProof's recursion:
+// proof = [h2, h3, hashChildren(h0,h1)], from which both the old root
+// (size 3) and the new root (size 4) must be independently derivable.
+func TestConsistencyProofNonPowerOfTwo(t *testing.T) {
+	h := testLeafHashes(4)
+	oldRoot := merkleRoot(h[:3])
+	newRoot := merkleRoot(h[:4])
+
+	proof := subProof(h[:4], 3, true)
+	if len(proof) != 3 {
+		t.Fatalf("len(proof) = %d, want 3", len(proof))
+	}
+
+	h01 := proof[2]
+	derivedOldRoot := hashChildren(h01, proof[0])
+	if string(derivedOldRoot) != string(oldRoot) {
+		t.Fatalf("derived old root = %x, want %x", derivedOldRoot, oldRoot)
+	}
+
+	derivedNewRoot := hashChildren(h01, hashChildren(proof[0], proof[1]))
+	if string(derivedNewRoot) != string(newRoot) {
+		t.Fatalf("derived new root = %x, want %x", derivedNewRoot, newRoot)
+	}
+}
+
+// TestTransparencyLogAppendInclusionConsistency drives TransparencyLog's
+// public API end to end: append a run of entries, fetch an inclusion
+// proof for one of them, and confirm a consistency proof between two
+// points in the run reconstructs the way TestConsistencyProof* above
+// shows it should.
+func TestTransparencyLogAppendInclusionConsistency(t *testing.T) {
+	dir := t.TempDir()
+	_, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tlog, err := NewTransparencyLog(filepath.Join(dir, "audit.log"), priv)
+	if err != nil {
+		t.Fatalf("NewTransparencyLog: %v", err)
+	}
+
+	var leafHashes [][]byte
+	for i := 0; i < 5; i++ {
+		h, seq, err := tlog.Append(auditKindMessage, "node-1", "req-1", []byte{byte(i)})
+		if err != nil {
+			t.Fatalf("Append: %v", err)
+		}
+		if seq != int64(i) {
+			t.Fatalf("Append seq = %d, want %d", seq, i)
+		}
+		leafHashes = append(leafHashes, h)
+	}
+
+	sth := tlog.STH()
+	if sth.TreeSize != 5 {
+		t.Fatalf("TreeSize = %d, want 5", sth.TreeSize)
+	}
+	sig, err := hex.DecodeString(sth.Signature)
+	if err != nil {
+		t.Fatalf("decoding STH signature: %v", err)
+	}
+	if !ed25519.Verify(priv.Public().(ed25519.PublicKey), sthSigningInput(sth), sig) {
+		t.Fatal("STH signature does not verify against the signing key")
+	}
+
+	index, proof, err := tlog.InclusionProof(leafHashes[3], 5)
+	if err != nil {
+		t.Fatalf("InclusionProof: %v", err)
+	}
+	if index != 3 {
+		t.Fatalf("InclusionProof index = %d, want 3", index)
+	}
+	if got := hex.EncodeToString(rootFromInclusionProof(leafHashes[3], 3, 5, proof)); got != sth.RootHash {
+		t.Fatalf("reconstructed root %x does not match published STH root %s", got, sth.RootHash)
+	}
+
+	// Consistency from tree size 3 to 5: subProof yields [h2, h3, h01, h4].
+	consistency, err := tlog.ConsistencyProof(3, 5)
+	if err != nil {
+		t.Fatalf("ConsistencyProof: %v", err)
+	}
+	if len(consistency) != 4 {
+		t.Fatalf("len(consistency proof) = %d, want 4", len(consistency))
+	}
+	h01 := consistency[2]
+	if got, want := hashChildren(h01, consistency[0]), merkleRoot(leafHashes[:3]); string(got) != string(want) {
+		t.Fatalf("derived size-3 root = %x, want %x", got, want)
+	}
+	newRoot := hashChildren(hashChildren(h01, hashChildren(consistency[0], consistency[1])), consistency[3])
+	if got := hex.EncodeToString(newRoot); got != sth.RootHash {
+		t.Fatalf("derived size-5 root %s does not match published STH root %s", got, sth.RootHash)
+	}
+}
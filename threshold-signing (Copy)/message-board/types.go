@@ -1,10 +1,19 @@
 package main
 
 import (
+	"fmt"
 	"sync"
 	"time"
 )
 
+// Supported signature schemes for a SigningRequest/PartialSignatureMessage.
+// SchemeECDSASecp256k1 is the default and is used when Scheme is left empty,
+// preserving compatibility with requests created before schemes existed.
+const (
+	SchemeECDSASecp256k1 = "ecdsa_secp256k1"
+	SchemeBLS12381       = "bls12381"
+)
+
 // SigningRequest represents a request to create a threshold signature
 // It tracks the message to sign, current status, and the final signature
 type SigningRequest struct {
@@ -12,10 +21,23 @@ type SigningRequest struct {
 	ID string `json:"id"`
 	// Message is the data to be signed
 	Message string `json:"message"`
+	// Scheme selects the signature scheme used for this request
+	// ("ecdsa_secp256k1" or "bls12381"). Defaults to "ecdsa_secp256k1" when empty.
+	Scheme string `json:"scheme,omitempty"`
+	// RequestedBy is the authenticated subject (JWT "sub" claim) the API
+	// Gateway recorded this request as coming from, or empty if the board
+	// was called directly without going through the gateway's OIDC layer.
+	RequestedBy string `json:"requested_by,omitempty"`
 	// Status indicates the current state: "pending", "in_progress", "completed", or "failed"
 	Status string `json:"status"`
 	// Signature contains the final threshold signature (only set when Status is "completed")
 	Signature string `json:"signature,omitempty"`
+	// State is this request's position in the protocol FSM (see State)
+	State State `json:"state"`
+	// ExpectedRound is the Round value PostMessage requires next, while
+	// State is one of the dkg_* rounds. Meaningless once State reaches
+	// sign_partial or later.
+	ExpectedRound int `json:"expected_round"`
 	// CreatedAt is when this request was created
 	CreatedAt time.Time `json:"created_at"`
 	// UpdatedAt is when this request was last modified
@@ -39,6 +61,16 @@ type NodeMessage struct {
 	Payload string `json:"payload"`
 	// CreatedAt is when this message was posted
 	CreatedAt time.Time `json:"created_at"`
+	// Signature is a hex-encoded ed25519 signature from FromNode over the
+	// JSON array encoding (see signedParts) of [RequestID, FromNode,
+	// ToNode, Round, Payload, CreatedAt] (CreatedAt formatted as
+	// RFC3339Nano), verified against the key FromNode registered via POST
+	// /nodes.
+	Signature string `json:"signature"`
+	// SignerPub is the hex-encoded ed25519 public key that verified
+	// Signature, embedded so a recipient can re-check authorship with
+	// VerifyNodeMessage without trusting the board.
+	SignerPub string `json:"signer_pub"`
 }
 
 // PresignatureRequest represents a request to generate a presignature
@@ -48,6 +80,10 @@ type PresignatureRequest struct {
 	ID string `json:"id"`
 	// Status indicates the current state: "pending", "in_progress", "completed", or "failed"
 	Status string `json:"status"`
+	// State is this request's position in the protocol FSM (see State)
+	State State `json:"state"`
+	// ExpectedRound is the Round value PostPresignatureMessage requires next
+	ExpectedRound int `json:"expected_round"`
 	// CreatedAt is when this request was created
 	CreatedAt time.Time `json:"created_at"`
 	// UpdatedAt is when this request was last modified
@@ -63,10 +99,20 @@ type PartialSignatureMessage struct {
 	RequestID string `json:"request_id"`
 	// FromNode is the identifier of the node that sent this partial signature
 	FromNode string `json:"from_node"`
+	// Scheme identifies which combiner should consume this partial signature
+	// ("ecdsa_secp256k1" or "bls12381"). Defaults to "ecdsa_secp256k1" when empty.
+	Scheme string `json:"scheme,omitempty"`
 	// Payload contains the partial signature data (JSON-encoded)
 	Payload string `json:"payload"`
 	// CreatedAt is when this partial signature was posted
 	CreatedAt time.Time `json:"created_at"`
+	// Signature is a hex-encoded ed25519 signature from FromNode over the
+	// JSON array encoding (see signedParts) of [RequestID, FromNode,
+	// Payload, CreatedAt] (CreatedAt formatted as RFC3339Nano), verified
+	// against the key FromNode registered via POST /nodes.
+	Signature string `json:"signature"`
+	// SignerPub is the hex-encoded ed25519 public key that verified Signature.
+	SignerPub string `json:"signer_pub"`
 }
 
 // Store manages all signing requests, presignature requests, and messages
@@ -89,13 +135,77 @@ type Store struct {
 	partialSignatures map[string]*PartialSignatureMessage
 	// partialSignaturesByRequest indexes partial signatures by request ID
 	partialSignaturesByRequest map[string][]*PartialSignatureMessage
-	// publicKey stores the shared public key (set by first node after keygen)
+	// publicKey stores the shared ECDSA secp256k1 public key (set by first node after keygen)
 	publicKey string
+	// blsPublicKey stores the shared BLS12-381 public key (set by first node after BLS keygen)
+	blsPublicKey string
+	// quorumSize is the minimum number of distinct FromNode senders
+	// required for a round before the FSM advances to the next state. The
+	// effective quorum (see roundQuorum) is never less than the number of
+	// nodes actually registered, so an operator who forgets to raise it for
+	// a larger deployment doesn't end up with the FSM advancing before
+	// every participant has posted.
+	quorumSize int
+	// nodeKeys holds each node's registered ed25519 identity public key,
+	// used to authenticate PostMessage/PostPresignatureMessage/PostPartialSignature
+	nodeKeys *KeyStore
+	// nodeJWKs holds each node's registered JWK, used to verify the compact
+	// JWS carried in PostMessage/PostPresignatureMessage/PostPartialSignature's
+	// payload field (see jws.go)
+	nodeJWKs *JWKStore
+	// log is the durable, replayable backend every mutation is appended to
+	// before it is applied to the maps above. See MessageLog.
+	log MessageLog
+	// auditLog is the tamper-evident transparency log that signing request
+	// creation, messages, presignature messages, partial signatures,
+	// signature sets and public keys are also appended to (status updates
+	// and presignature request creation are not), or nil if none was
+	// configured (see
+	// MPC_BOARD_AUDIT_LOG_PATH). Unlike log, it is not used to rebuild the
+	// maps above on replay; it exists so nodes and external auditors can
+	// verify no message was retroactively altered or dropped.
+	auditLog *TransparencyLog
+	// messageSubs holds live Subscribe calls, keyed by request ID, notified
+	// from applyMessage while s.mu is held.
+	messageSubs map[string][]*messageSub
+	// presignatureMessageSubs holds live SubscribePresignatureMessages
+	// calls, keyed by request ID, notified from applyPresignatureMessage.
+	presignatureMessageSubs map[string][]*messageSub
+	// partialSignatureSubs holds live SubscribePartialSignatures calls,
+	// keyed by request ID, notified from applyPartialSignature.
+	partialSignatureSubs map[string][]*partialSignatureSub
+	// statusSubs holds live SubscribeStatus calls, keyed by request ID,
+	// notified from applyStatusUpdate and applySignatureSet.
+	statusSubs map[string][]*statusSub
 }
 
-// NewStore creates a new empty Store
+// NewStore creates a new empty Store using defaultQuorumSize and an
+// InMemoryLog (no durability across a process restart), with no audit log
 func NewStore() *Store {
-	return &Store{
+	return NewStoreWithQuorum(defaultQuorumSize)
+}
+
+// NewStoreWithQuorum creates a new empty Store that requires quorumSize
+// distinct FromNode senders per round before the FSM advances, backed by an
+// InMemoryLog, with no audit log
+func NewStoreWithQuorum(quorumSize int) *Store {
+	store, err := NewStoreWithLog(quorumSize, NewInMemoryLog(), nil)
+	if err != nil {
+		// InMemoryLog.Replay never fails on an empty log
+		panic(err)
+	}
+	return store
+}
+
+// NewStoreWithLog creates a Store backed by log, replaying any entries
+// already in it to rebuild requests, presignatureRequests,
+// messagesByRequest, presignatureMessagesByRequest,
+// partialSignaturesByRequest, publicKey, and blsPublicKey. Use this to
+// recover a MessageBoard's state after a restart by pointing log at the
+// same backing store (file, Kafka topic, ...) the previous process used.
+// auditLog may be nil to disable the tamper-evident transparency log.
+func NewStoreWithLog(quorumSize int, log MessageLog, auditLog *TransparencyLog) (*Store, error) {
+	s := &Store{
 		requests:                      make(map[string]*SigningRequest),
 		presignatureRequests:          make(map[string]*PresignatureRequest),
 		messages:                      make(map[string]*NodeMessage),
@@ -104,5 +214,18 @@ func NewStore() *Store {
 		presignatureMessagesByRequest: make(map[string][]*NodeMessage),
 		partialSignatures:             make(map[string]*PartialSignatureMessage),
 		partialSignaturesByRequest:    make(map[string][]*PartialSignatureMessage),
+		quorumSize:                    quorumSize,
+		nodeKeys:                      NewKeyStore(),
+		nodeJWKs:                      NewJWKStore(),
+		log:                           log,
+		auditLog:                      auditLog,
+		messageSubs:                   make(map[string][]*messageSub),
+		presignatureMessageSubs:       make(map[string][]*messageSub),
+		partialSignatureSubs:          make(map[string][]*partialSignatureSub),
+		statusSubs:                    make(map[string][]*statusSub),
+	}
+	if err := s.replay(); err != nil {
+		return nil, fmt.Errorf("replaying message log: %w", err)
 	}
+	return s, nil
 }